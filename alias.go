@@ -0,0 +1,261 @@
+package marqo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrAliasConflict is returned when an alias does not point to the expected
+// index while swapping it
+var ErrAliasConflict = errors.New("marqo: alias points to another index")
+
+// AliasStore maps index aliases to physical indexes. Implementations must be
+// safe for concurrent use.
+type AliasStore interface {
+	// Resolve returns the index of the alias, and false if it is not an alias
+	Resolve(ctx context.Context, alias string) (string, bool, error)
+	// Swap points the alias to newIndex if it points to oldIndex, or if it
+	// does not exist and oldIndex is empty, otherwise it returns
+	// ErrAliasConflict
+	Swap(ctx context.Context, alias, oldIndex, newIndex string) error
+	// Delete removes the alias
+	Delete(ctx context.Context, alias string) error
+	// List returns every alias and its index
+	List(ctx context.Context) (map[string]string, error)
+}
+
+// WithAliasStore resolves the index names passed to the client with store.
+// The index names of every method are resolved, except CreateIndex which
+// creates physical indexes. DeleteIndex rejects aliases with
+// ErrInvalidArgument rather than deleting the index they shadow.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithAliasStore(marqo.NewFileAliasStore("aliases.json")),
+//	)
+func WithAliasStore(store AliasStore) func(*Client) {
+	return func(c *Client) {
+		c.aliases = store
+	}
+}
+
+// physicalIndexKey is the context key marking the index names as physical
+type physicalIndexKey struct{}
+
+// withPhysicalIndex returns a context in which the index names passed to the
+// client are not resolved, for the names which are physical already, such
+// as the ones listed by ListIndexes or resolved before. An alias may shadow
+// the physical index of the same name.
+func withPhysicalIndex(ctx context.Context) context.Context {
+	return context.WithValue(ctx, physicalIndexKey{}, true)
+}
+
+// resolveIndex returns the physical index of indexName, which is returned
+// as is if it is not an alias or ctx is from withPhysicalIndex
+func (c *Client) resolveIndex(ctx context.Context, indexName string) (string, error) {
+	if c.aliases == nil || indexName == "" {
+		return indexName, nil
+	}
+	if physical, _ := ctx.Value(physicalIndexKey{}).(bool); physical {
+		return indexName, nil
+	}
+	index, ok, err := c.aliases.Resolve(ctx, indexName)
+	if err != nil {
+		return "", fmt.Errorf("error resolving index alias %s: %w", indexName, err)
+	}
+	if !ok {
+		return indexName, nil
+	}
+	return index, nil
+}
+
+// rejectAlias returns ErrInvalidArgument if indexName is an alias
+func (c *Client) rejectAlias(ctx context.Context, indexName string) error {
+	if c.aliases == nil {
+		return nil
+	}
+	_, isAlias, err := c.aliases.Resolve(ctx, indexName)
+	if err != nil {
+		return fmt.Errorf("error resolving index alias %s: %w", indexName, err)
+	}
+	if isAlias {
+		return fmt.Errorf("%w: %s is an alias, delete the alias or the index it points to", ErrInvalidArgument, indexName)
+	}
+	return nil
+}
+
+// SetAlias points the alias to the index, whatever it pointed to before.
+// It returns an error if the client has no alias store.
+func (c *Client) SetAlias(ctx context.Context, alias, indexName string) (err error) {
+	ctx, span := c.startSpan(ctx, "SetAlias")
+	defer endSpan(span, &err)
+	if c.aliases == nil {
+		return fmt.Errorf("%w: client has no alias store", ErrInvalidArgument)
+	}
+	for {
+		current, _, err := c.aliases.Resolve(ctx, alias)
+		if err != nil {
+			return err
+		}
+		err = c.aliases.Swap(ctx, alias, current, indexName)
+		if !errors.Is(err, ErrAliasConflict) {
+			return err
+		}
+	}
+}
+
+// MemoryAliasStore is an AliasStore kept in memory
+type MemoryAliasStore struct {
+	mu      sync.Mutex
+	aliases map[string]string
+}
+
+// NewMemoryAliasStore returns an empty in-memory alias store
+func NewMemoryAliasStore() *MemoryAliasStore {
+	return &MemoryAliasStore{aliases: make(map[string]string)}
+}
+
+// Resolve returns the index of the alias
+func (s *MemoryAliasStore) Resolve(_ context.Context, alias string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, ok := s.aliases[alias]
+	return index, ok, nil
+}
+
+// Swap points the alias to newIndex if it points to oldIndex
+func (s *MemoryAliasStore) Swap(_ context.Context, alias, oldIndex, newIndex string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aliases[alias] != oldIndex {
+		return fmt.Errorf("%w: %s points to %q, not %q", ErrAliasConflict, alias, s.aliases[alias], oldIndex)
+	}
+	s.aliases[alias] = newIndex
+	return nil
+}
+
+// Delete removes the alias
+func (s *MemoryAliasStore) Delete(_ context.Context, alias string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.aliases, alias)
+	return nil
+}
+
+// List returns every alias and its index
+func (s *MemoryAliasStore) List(_ context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases := make(map[string]string, len(s.aliases))
+	for alias, index := range s.aliases {
+		aliases[alias] = index
+	}
+	return aliases, nil
+}
+
+// FileAliasStore is an AliasStore kept in a JSON file, which maps the
+// aliases to their index. The file is read on every call, so that aliases
+// swapped by other processes are seen, and replaced atomically on every
+// change. Swaps are only atomic within a process.
+type FileAliasStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileAliasStore returns an alias store kept in the file at path, which
+// is created on the first change
+func NewFileAliasStore(path string) *FileAliasStore {
+	return &FileAliasStore{path: path}
+}
+
+// read returns the aliases of the file, empty if it does not exist
+func (s *FileAliasStore) read() (map[string]string, error) {
+	aliases := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("error decoding alias file %s: %w", s.path, err)
+	}
+	return aliases, nil
+}
+
+// write replaces the file with aliases
+func (s *FileAliasStore) write(aliases map[string]string) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Resolve returns the index of the alias
+func (s *FileAliasStore) Resolve(_ context.Context, alias string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases, err := s.read()
+	if err != nil {
+		return "", false, err
+	}
+	index, ok := aliases[alias]
+	return index, ok, nil
+}
+
+// Swap points the alias to newIndex if it points to oldIndex
+func (s *FileAliasStore) Swap(_ context.Context, alias, oldIndex, newIndex string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases, err := s.read()
+	if err != nil {
+		return err
+	}
+	if aliases[alias] != oldIndex {
+		return fmt.Errorf("%w: %s points to %q, not %q", ErrAliasConflict, alias, aliases[alias], oldIndex)
+	}
+	aliases[alias] = newIndex
+	return s.write(aliases)
+}
+
+// Delete removes the alias
+func (s *FileAliasStore) Delete(_ context.Context, alias string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[alias]; !ok {
+		return nil
+	}
+	delete(aliases, alias)
+	return s.write(aliases)
+}
+
+// List returns every alias and its index
+func (s *FileAliasStore) List(_ context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
@@ -0,0 +1,314 @@
+package marqo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestAliasStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) AliasStore{
+		"memory": func(t *testing.T) AliasStore { return NewMemoryAliasStore() },
+		"file": func(t *testing.T) AliasStore {
+			return NewFileAliasStore(filepath.Join(t.TempDir(), "aliases.json"))
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+
+			if _, ok, err := store.Resolve(ctx, "products"); ok || err != nil {
+				t.Fatalf("Resolve() of a missing alias = %v, %v", ok, err)
+			}
+			if err := store.Swap(ctx, "products", "", "products-v1"); err != nil {
+				t.Fatalf("Swap() error = %v", err)
+			}
+			if err := store.Swap(ctx, "products", "", "products-v2"); !errors.Is(err, ErrAliasConflict) {
+				t.Errorf("Swap() of an existing alias error = %v, want ErrAliasConflict", err)
+			}
+			if err := store.Swap(ctx, "products", "products-v1", "products-v2"); err != nil {
+				t.Fatalf("Swap() error = %v", err)
+			}
+			if index, ok, err := store.Resolve(ctx, "products"); index != "products-v2" || !ok || err != nil {
+				t.Errorf("Resolve() = %v, %v, %v, want products-v2", index, ok, err)
+			}
+			if err := store.Swap(ctx, "users", "", "users-v1"); err != nil {
+				t.Fatalf("Swap() error = %v", err)
+			}
+			if err := store.Delete(ctx, "users"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			aliases, err := store.List(ctx)
+			if err != nil || !reflect.DeepEqual(aliases, map[string]string{"products": "products-v2"}) {
+				t.Errorf("List() = %v, %v", aliases, err)
+			}
+		})
+	}
+
+	t.Run("file is shared", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "aliases.json")
+		if err := NewFileAliasStore(path).Swap(context.Background(), "products", "", "products-v1"); err != nil {
+			t.Fatalf("Swap() error = %v", err)
+		}
+		index, ok, err := NewFileAliasStore(path).Resolve(context.Background(), "products")
+		if index != "products-v1" || !ok || err != nil {
+			t.Errorf("Resolve() = %v, %v, %v, want products-v1", index, ok, err)
+		}
+	})
+}
+
+// getMockServerForReindex returns a server holding the documents of the
+// index products-v1, recording the requests and the documents copied to
+// products-v2, whose stats report extra more documents than were copied
+func getMockServerForReindex(extra int, mu *sync.Mutex, requests *[]string, copied *[]map[string]interface{}) *httptest.Server {
+	documents := []map[string]interface{}{
+		{"_id": "1", "title": "one", "_score": 1.0},
+		{"_id": "2", "title": "two", "_score": 1.0},
+		{"_id": "3", "title": "three", "_score": 1.0},
+	}
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			*requests = append(*requests, r.Method+" "+r.URL.Path)
+
+			var resp interface{}
+			switch {
+			case r.URL.Path == "/indexes":
+				resp = map[string]interface{}{"results": []map[string]string{{"index_name": "products-v1"}, {"index_name": "products-v2"}}}
+			case r.URL.Path == "/indexes/products-v1/search":
+				var body struct {
+					Limit  int `json:"limit"`
+					Offset int `json:"offset"`
+				}
+				// disabeling lint as this is a mock server
+				// nolint
+				json.NewDecoder(r.Body).Decode(&body)
+				hits := []map[string]interface{}{}
+				if body.Offset < len(documents) {
+					hits = documents[body.Offset:min(body.Offset+body.Limit, len(documents))]
+				}
+				resp = map[string]interface{}{"hits": hits}
+			case r.URL.Path == "/indexes/products-v1/documents":
+				var ids []string
+				// disabeling lint as this is a mock server
+				// nolint
+				json.NewDecoder(r.Body).Decode(&ids)
+				var results []map[string]interface{}
+				for _, id := range ids {
+					n, _ := strconv.Atoi(id)
+					if n >= 1 && n <= len(documents) {
+						results = append(results, documents[n-1])
+					} else {
+						results = append(results, map[string]interface{}{"_id": id, "_found": false})
+					}
+				}
+				resp = map[string]interface{}{"results": results}
+			case r.URL.Path == "/indexes/products-v2/documents":
+				var body struct {
+					Documents []map[string]interface{} `json:"documents"`
+				}
+				// disabeling lint as this is a mock server
+				// nolint
+				json.NewDecoder(r.Body).Decode(&body)
+				*copied = append(*copied, body.Documents...)
+				resp = map[string]interface{}{"errors": false}
+			case r.URL.Path == "/indexes/products-v1/stats":
+				resp = map[string]int{"numberOfDocuments": len(documents)}
+			case r.URL.Path == "/indexes/products-v2/stats":
+				resp = map[string]int{"numberOfDocuments": len(*copied) + extra}
+			case r.URL.Path == "/indexes/products-v2/health":
+				resp = map[string]interface{}{"status": "green", "backend": map[string]interface{}{"status": "green"}}
+			default:
+				resp = map[string]interface{}{"acknowledged": true}
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			json.NewEncoder(w).Encode(resp)
+		}))
+}
+
+func TestClient_Reindex(t *testing.T) {
+	tests := []struct {
+		name         string
+		extra        int
+		documentIDs  []string
+		deleteOld    bool
+		wantErr      bool
+		wantIndex    string
+		wantCopied   []string
+		wantRequests []string
+	}{
+		{
+			name:       "copies every document and deletes old index",
+			deleteOld:  true,
+			wantIndex:  "products-v2",
+			wantCopied: []string{"1", "2", "3"},
+			wantRequests: []string{
+				"POST /indexes/products-v2",
+				"GET /indexes",
+				"GET /indexes/products-v2/health",
+				"GET /indexes/products-v1/stats",
+				"POST /indexes/products-v1/search",
+				"GET /indexes/products-v1/documents",
+				"POST /indexes/products-v2/documents",
+				"POST /indexes/products-v1/search",
+				"GET /indexes/products-v1/documents",
+				"POST /indexes/products-v2/documents",
+				"GET /indexes/products-v2/stats",
+				"GET /indexes/products-v1/stats",
+				"DELETE /indexes/products-v1",
+			},
+		},
+		{
+			name:        "copies document ids only",
+			documentIDs: []string{"3", "1"},
+			wantIndex:   "products-v2",
+			wantCopied:  []string{"3", "1"},
+			wantRequests: []string{
+				"POST /indexes/products-v2",
+				"GET /indexes",
+				"GET /indexes/products-v2/health",
+				"GET /indexes/products-v1/documents",
+				"POST /indexes/products-v2/documents",
+				"GET /indexes/products-v2/stats",
+			},
+		},
+		{
+			name:        "document ids not found do not swap alias",
+			documentIDs: []string{"1", "3", "4"},
+			wantErr:     true,
+			wantIndex:   "products-v1",
+			wantCopied:  []string{"1", "3"},
+			wantRequests: []string{
+				"POST /indexes/products-v2",
+				"GET /indexes",
+				"GET /indexes/products-v2/health",
+				"GET /indexes/products-v1/documents",
+				"POST /indexes/products-v2/documents",
+				"GET /indexes/products-v1/documents",
+				"DELETE /indexes/products-v2",
+			},
+		},
+		{
+			name:       "count mismatch does not swap alias",
+			extra:      1,
+			deleteOld:  true,
+			wantErr:    true,
+			wantIndex:  "products-v1",
+			wantCopied: []string{"1", "2", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests []string
+				copied   []map[string]interface{}
+			)
+			mockServer := getMockServerForReindex(tt.extra, &mu, &requests, &copied)
+			defer mockServer.Close()
+
+			store := NewMemoryAliasStore()
+			c, err := NewClient(mockServer.URL, WithAliasStore(store))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if err := c.SetAlias(context.Background(), "products", "products-v1"); err != nil {
+				t.Fatalf("SetAlias() error = %v", err)
+			}
+			reindexResp, err := c.Reindex(context.Background(), &ReindexRequest{
+				Alias:       "products",
+				NewIndex:    &CreateIndexRequest{IndexName: "products-v2"},
+				DocumentIDs: tt.documentIDs,
+				BatchSize:   2,
+				DeleteOld:   tt.deleteOld,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.Reindex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// nil when it fails before creating the new index
+			if reindexResp != nil && (reindexResp.OldIndex != "products-v1" || reindexResp.DocumentsCopied != len(tt.wantCopied)) {
+				t.Errorf("Client.Reindex() = %+v", reindexResp)
+			}
+			if index, _, _ := store.Resolve(context.Background(), "products"); index != tt.wantIndex {
+				t.Errorf("alias points to %s, want %s", index, tt.wantIndex)
+			}
+
+			var ids []string
+			for _, document := range copied {
+				ids = append(ids, document["_id"].(string))
+				if _, ok := document["_score"]; ok || document["title"] == nil {
+					t.Errorf("copied document = %v", document)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantCopied) {
+				t.Errorf("copied documents = %v, want %v", ids, tt.wantCopied)
+			}
+			if tt.wantRequests != nil && !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_AliasResolution(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		copied   []map[string]interface{}
+	)
+	mockServer := getMockServerForReindex(0, &mu, &requests, &copied)
+	defer mockServer.Close()
+
+	store := NewMemoryAliasStore()
+	c, err := NewClient(mockServer.URL, WithAliasStore(store))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := c.SetAlias(context.Background(), "products", "products-v1"); err != nil {
+		t.Fatalf("SetAlias() error = %v", err)
+	}
+	q := "shoes"
+	if _, err := c.Search(&SearchRequest{IndexName: "products", Q: &q}); err != nil {
+		t.Fatalf("Client.Search() error = %v", err)
+	}
+	stats, err := c.GetIndexStats(&GetIndexStatsRequest{IndexName: "products"})
+	if err != nil || stats.NumberOfDocuments != 3 {
+		t.Fatalf("Client.GetIndexStats() = %+v, %v", stats, err)
+	}
+	if _, err := c.GetIndexStats(&GetIndexStatsRequest{IndexName: "products-v2"}); err != nil {
+		t.Fatalf("Client.GetIndexStats() error = %v", err)
+	}
+	if err := c.WaitForIndexReady(context.Background(), "products"); err != nil {
+		t.Fatalf("Client.WaitForIndexReady() error = %v", err)
+	}
+	ensureResult, err := c.EnsureIndex(context.Background(), &CreateIndexRequest{IndexName: "products"}, DriftPolicyAccept)
+	if err != nil || ensureResult.Created {
+		t.Fatalf("Client.EnsureIndex() = %+v, %v, want the existing index", ensureResult, err)
+	}
+	if _, err := c.DeleteIndex(&DeleteIndexRequest{IndexName: "products"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Client.DeleteIndex() error = %v, want ErrInvalidArgument", err)
+	}
+	want := []string{
+		"POST /indexes/products-v1/search",
+		"GET /indexes/products-v1/stats",
+		"GET /indexes/products-v2/stats",
+		"GET /indexes",
+		"GET /indexes/products-v1/health",
+		"GET /indexes",
+		"GET /indexes/products-v1/settings",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
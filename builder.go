@@ -0,0 +1,330 @@
+package marqo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SplitMethod is the method to split text into chunks
+type SplitMethod string
+
+// Split methods of TextPreprocessing
+const (
+	SplitMethodSentence  SplitMethod = "sentence"
+	SplitMethodWord      SplitMethod = "word"
+	SplitMethodCharacter SplitMethod = "character"
+	SplitMethodPassage   SplitMethod = "passage"
+)
+
+// PatchMethod is the method by which images are chunked
+type PatchMethod string
+
+// Patch methods of ImagePreprocessing
+const (
+	PatchMethodSimple PatchMethod = "simple"
+	PatchMethodFRCNN  PatchMethod = "frcnn"
+)
+
+// SpaceType is the function used to measure the distance between vectors
+type SpaceType string
+
+// Space types of ANNParameters, Marqo 1.x and 2.x have their own names
+const (
+	SpaceTypeCosineSimilarity SpaceType = "cosinesimil"
+	SpaceTypeL1               SpaceType = "l1"
+	SpaceTypeL2               SpaceType = "l2"
+	SpaceTypeLInf             SpaceType = "linf"
+
+	// Marqo 2.x only space types
+	SpaceTypeEuclidean            SpaceType = "euclidean"
+	SpaceTypeAngular              SpaceType = "angular"
+	SpaceTypeDotProduct           SpaceType = "dotproduct"
+	SpaceTypePrenormalizedAngular SpaceType = "prenormalized-angular"
+	SpaceTypeHamming              SpaceType = "hamming"
+)
+
+// ModelName is the name of a model which vectorises the documents
+type ModelName string
+
+// Models used by the index presets, any other Marqo model name or custom
+// model can be used too
+const (
+	ModelMiniLML6       ModelName = "hf/all_datasets_v4_MiniLM-L6"
+	ModelE5Base         ModelName = "hf/e5-base-v2"
+	ModelE5Large        ModelName = "hf/e5-large-v2"
+	ModelOpenCLIPViTB32 ModelName = "open_clip/ViT-B-32/laion2b_s34b_b79k"
+)
+
+// IndexPreset is a named template of index settings
+type IndexPreset string
+
+// Index presets of NewIndexBuilderFromPreset
+const (
+	// PresetTextE5Large is for text search with e5-large-v2
+	PresetTextE5Large IndexPreset = "text-e5-large"
+	// PresetImageCLIPViTB32 is for text to image search with CLIP ViT-B/32
+	PresetImageCLIPViTB32 IndexPreset = "image-clip-vit-b32"
+	// PresetEcommerceHybrid is for product search with images and text,
+	// with a denser graph for hybrid search
+	PresetEcommerceHybrid IndexPreset = "ecommerce-hybrid"
+	// PresetLowMemory is a small model, a sparse graph, a single shard on
+	// Marqo 1.x and bfloat16 vectors on Marqo 2.x
+	PresetLowMemory IndexPreset = "low-memory"
+)
+
+// indexPresets are the settings of every IndexPreset
+var indexPresets = map[IndexPreset]func(b *IndexBuilder){
+	PresetTextE5Large: func(b *IndexBuilder) {
+		b.Model(ModelE5Large).
+			NormalizeEmbeddings(true).
+			TextPreprocessing(SplitMethodSentence, 2, 0).
+			SpaceType(SpaceTypeCosineSimilarity)
+	},
+	PresetImageCLIPViTB32: func(b *IndexBuilder) {
+		b.Model(ModelOpenCLIPViTB32).
+			NormalizeEmbeddings(true).
+			TreatURLsAndPointersAsImages(true).
+			ImagePreprocessing(PatchMethodSimple).
+			SpaceType(SpaceTypeCosineSimilarity)
+	},
+	PresetEcommerceHybrid: func(b *IndexBuilder) {
+		b.Model(ModelOpenCLIPViTB32).
+			NormalizeEmbeddings(true).
+			TreatURLsAndPointersAsImages(true).
+			TextPreprocessing(SplitMethodSentence, 2, 0).
+			ImagePreprocessing(PatchMethodSimple).
+			SpaceType(SpaceTypeCosineSimilarity).
+			HNSW(512, 16)
+	},
+	PresetLowMemory: func(b *IndexBuilder) {
+		// every dialect drops the settings it does not support, the shards
+		// and replicas of Marqo 2.x are set by the deployment and Marqo 1.x
+		// only stores float vectors
+		b.Model(ModelMiniLML6).
+			NormalizeEmbeddings(true).
+			TextPreprocessing(SplitMethodSentence, 2, 0).
+			SpaceType(SpaceTypeCosineSimilarity).
+			HNSW(64, 8).
+			VectorNumericType(VectorNumericTypeBfloat16).
+			Shards(1).
+			Replicas(0)
+	},
+}
+
+// IndexBuilder builds a CreateIndexRequest with typed settings. Errors are
+// collected and returned by Build.
+//
+// Example usage:
+//
+//	createIndexReq, errs := marqo.NewIndexBuilder("example_index").
+//	    Model(marqo.ModelE5Base).
+//	    TextPreprocessing(marqo.SplitMethodWord, 128, 16).
+//	    HNSW(256, 16).
+//	    Build()
+//	if len(errs) > 0 {
+//	    log.Fatalf("Invalid index settings: %v", errors.Join(errs...))
+//	}
+//	resp, err := client.CreateIndex(createIndexReq)
+type IndexBuilder struct {
+	req  *CreateIndexRequest
+	errs []error
+}
+
+// NewIndexBuilder returns a builder of the index indexName with the server
+// default settings
+func NewIndexBuilder(indexName string) *IndexBuilder {
+	return &IndexBuilder{
+		req: &CreateIndexRequest{
+			IndexName:     indexName,
+			IndexDefaults: &IndexDefaults{},
+		},
+	}
+}
+
+// NewIndexBuilderFromPreset returns a builder of the index indexName with
+// the settings of preset, which can be changed further
+func NewIndexBuilderFromPreset(indexName string, preset IndexPreset) *IndexBuilder {
+	b := NewIndexBuilder(indexName)
+	apply, ok := indexPresets[preset]
+	if !ok {
+		return b.invalid("unknown index preset %q", preset)
+	}
+	apply(b)
+	return b
+}
+
+// invalid records an error of the settings
+func (b *IndexBuilder) invalid(format string, args ...interface{}) *IndexBuilder {
+	b.errs = append(b.errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidArgument}, args...)...))
+	return b
+}
+
+// Model sets the model which vectorises the documents
+func (b *IndexBuilder) Model(model ModelName) *IndexBuilder {
+	if model == "" {
+		return b.invalid("model is empty")
+	}
+	name := string(model)
+	b.req.IndexDefaults.Model = &name
+	return b
+}
+
+// ModelProperties sets the properties of a custom model
+func (b *IndexBuilder) ModelProperties(properties ModelProperties) *IndexBuilder {
+	b.req.IndexDefaults.ModelProperties = &properties
+	return b
+}
+
+// NormalizeEmbeddings sets whether the embeddings have unit length
+func (b *IndexBuilder) NormalizeEmbeddings(normalize bool) *IndexBuilder {
+	b.req.IndexDefaults.NormalizeEmbeddings = &normalize
+	return b
+}
+
+// TreatURLsAndPointersAsImages sets whether images are fetched from URLs
+// and pointers
+func (b *IndexBuilder) TreatURLsAndPointersAsImages(treat bool) *IndexBuilder {
+	b.req.IndexDefaults.TreatURLsAndPointersAsImages = &treat
+	return b
+}
+
+// TextPreprocessing sets how text is split into chunks of splitLength units
+// of method, overlapping by splitOverlap units
+func (b *IndexBuilder) TextPreprocessing(method SplitMethod, splitLength, splitOverlap int) *IndexBuilder {
+	switch method {
+	case SplitMethodSentence, SplitMethodWord, SplitMethodCharacter, SplitMethodPassage:
+	default:
+		return b.invalid("unknown split method %q", method)
+	}
+	if splitLength < 1 {
+		return b.invalid("split length must be at least 1, got %d", splitLength)
+	}
+	if splitOverlap < 0 || splitOverlap >= splitLength {
+		return b.invalid("split overlap must be between 0 and split length %d, got %d", splitLength, splitOverlap)
+	}
+	splitMethod := string(method)
+	b.req.IndexDefaults.TextPreprocessing = &TextPreprocessing{
+		SplitLength:  &splitLength,
+		SplitOverlap: &splitOverlap,
+		SplitMethod:  &splitMethod,
+	}
+	return b
+}
+
+// ImagePreprocessing sets how images are chunked
+func (b *IndexBuilder) ImagePreprocessing(method PatchMethod) *IndexBuilder {
+	switch method {
+	case PatchMethodSimple, PatchMethodFRCNN:
+	default:
+		return b.invalid("unknown patch method %q", method)
+	}
+	patchMethod := string(method)
+	b.req.IndexDefaults.ImagePreprocessing = &ImagePreprocessing{PatchMethod: &patchMethod}
+	return b
+}
+
+// SpaceType sets the function used to measure the distance between vectors
+func (b *IndexBuilder) SpaceType(spaceType SpaceType) *IndexBuilder {
+	switch spaceType {
+	case SpaceTypeCosineSimilarity, SpaceTypeL1, SpaceTypeL2, SpaceTypeLInf,
+		SpaceTypeEuclidean, SpaceTypeAngular, SpaceTypeDotProduct, SpaceTypePrenormalizedAngular, SpaceTypeHamming:
+	default:
+		return b.invalid("unknown space type %q", spaceType)
+	}
+	name := string(spaceType)
+	b.annParameters().SpaceType = &name
+	return b
+}
+
+// HNSW sets the size of the dynamic list used during graph creation, from 2
+// to 4096, and the number of links of every element, from 2 to 100
+func (b *IndexBuilder) HNSW(efConstruction, m int) *IndexBuilder {
+	if efConstruction < 2 || efConstruction > 4096 {
+		return b.invalid("ef construction must be between 2 and 4096, got %d", efConstruction)
+	}
+	if m < 2 || m > 100 {
+		return b.invalid("m must be between 2 and 100, got %d", m)
+	}
+	b.annParameters().Parameters = &HSNWMethodParameters{
+		EFConstruction: &efConstruction,
+		M:              &m,
+	}
+	return b
+}
+
+// annParameters returns the ANN parameters of the request, creating them
+func (b *IndexBuilder) annParameters() *ANNParameters {
+	if b.req.IndexDefaults.ANNParameters == nil {
+		b.req.IndexDefaults.ANNParameters = &ANNParameters{}
+	}
+	return b.req.IndexDefaults.ANNParameters
+}
+
+// Shards sets the number of shards of a Marqo 1.x index
+func (b *IndexBuilder) Shards(shards int) *IndexBuilder {
+	if shards < 1 {
+		return b.invalid("number of shards must be at least 1, got %d", shards)
+	}
+	b.req.NumberOfShards = &shards
+	return b
+}
+
+// Replicas sets the number of replicas of a Marqo 1.x index
+func (b *IndexBuilder) Replicas(replicas int) *IndexBuilder {
+	if replicas < 0 {
+		return b.invalid("number of replicas must not be negative, got %d", replicas)
+	}
+	b.req.NumberOfReplicas = &replicas
+	return b
+}
+
+// VectorNumericType sets the numeric type of the vectors of a Marqo 2.x index
+func (b *IndexBuilder) VectorNumericType(numericType string) *IndexBuilder {
+	b.req.VectorNumericType = &numericType
+	return b
+}
+
+// Structured makes the index a Marqo 2.x structured index with fields, of
+// which tensorFields are vectorised
+func (b *IndexBuilder) Structured(fields []FieldDefinition, tensorFields ...string) *IndexBuilder {
+	indexType := IndexTypeStructured
+	b.req.Type = &indexType
+	b.req.AllFields = fields
+	b.req.TensorFields = tensorFields
+	return b
+}
+
+// Build validates the settings and returns the request, or every error
+// found in the settings. The errors match ErrInvalidArgument.
+func (b *IndexBuilder) Build() (*CreateIndexRequest, []error) {
+	errs := append([]error(nil), b.errs...)
+	if b.req.IndexName == "" {
+		errs = append(errs, fmt.Errorf("%w: index name is empty", ErrInvalidArgument))
+	}
+
+	defaults := b.req.IndexDefaults
+	if defaults.ANNParameters != nil && defaults.ANNParameters.SpaceType != nil &&
+		SpaceType(*defaults.ANNParameters.SpaceType) == SpaceTypePrenormalizedAngular &&
+		defaults.NormalizeEmbeddings != nil && !*defaults.NormalizeEmbeddings {
+		errs = append(errs, fmt.Errorf("%w: space type %s needs normalized embeddings", ErrInvalidArgument, SpaceTypePrenormalizedAngular))
+	}
+	if defaults.ModelProperties != nil && defaults.Model == nil {
+		errs = append(errs, fmt.Errorf("%w: model properties need a model name", ErrInvalidArgument))
+	}
+
+	if _, err := validateModel(b.req); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateIndexSchema(b.req); err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			errs = append(errs, joined.Unwrap()...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return b.req, nil
+}
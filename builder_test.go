@@ -0,0 +1,152 @@
+package marqo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIndexBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *IndexBuilder
+		want    func(req *CreateIndexRequest) bool
+		wantErr []string
+	}{
+		{
+			name: "custom settings",
+			builder: NewIndexBuilder("test").
+				Model(ModelE5Base).
+				TextPreprocessing(SplitMethodWord, 128, 16).
+				ImagePreprocessing(PatchMethodFRCNN).
+				SpaceType(SpaceTypeL2).
+				HNSW(256, 32).
+				Shards(2).
+				Replicas(1),
+			want: func(req *CreateIndexRequest) bool {
+				defaults := req.IndexDefaults
+				return req.IndexName == "test" && *defaults.Model == "hf/e5-base-v2" &&
+					*defaults.TextPreprocessing.SplitMethod == "word" &&
+					*defaults.TextPreprocessing.SplitLength == 128 &&
+					*defaults.TextPreprocessing.SplitOverlap == 16 &&
+					*defaults.ImagePreprocessing.PatchMethod == "frcnn" &&
+					*defaults.ANNParameters.SpaceType == "l2" &&
+					*defaults.ANNParameters.Parameters.EFConstruction == 256 &&
+					*defaults.ANNParameters.Parameters.M == 32 &&
+					*req.NumberOfShards == 2 && *req.NumberOfReplicas == 1
+			},
+		},
+		{
+			name:    "text e5 large preset",
+			builder: NewIndexBuilderFromPreset("test", PresetTextE5Large),
+			want: func(req *CreateIndexRequest) bool {
+				return *req.IndexDefaults.Model == "hf/e5-large-v2" &&
+					*req.IndexDefaults.TextPreprocessing.SplitMethod == "sentence"
+			},
+		},
+		{
+			name:    "image clip preset",
+			builder: NewIndexBuilderFromPreset("test", PresetImageCLIPViTB32),
+			want: func(req *CreateIndexRequest) bool {
+				return *req.IndexDefaults.Model == string(ModelOpenCLIPViTB32) &&
+					*req.IndexDefaults.TreatURLsAndPointersAsImages
+			},
+		},
+		{
+			name:    "ecommerce hybrid preset",
+			builder: NewIndexBuilderFromPreset("test", PresetEcommerceHybrid),
+			want: func(req *CreateIndexRequest) bool {
+				return *req.IndexDefaults.ANNParameters.Parameters.EFConstruction == 512 &&
+					*req.IndexDefaults.TreatURLsAndPointersAsImages
+			},
+		},
+		{
+			name:    "low memory preset with changes",
+			builder: NewIndexBuilderFromPreset("test", PresetLowMemory).HNSW(32, 4),
+			want: func(req *CreateIndexRequest) bool {
+				return *req.IndexDefaults.Model == string(ModelMiniLML6) &&
+					*req.IndexDefaults.ANNParameters.Parameters.M == 4 &&
+					*req.VectorNumericType == VectorNumericTypeBfloat16 &&
+					*req.NumberOfShards == 1 && *req.NumberOfReplicas == 0
+			},
+		},
+		{
+			name: "structured index",
+			builder: NewIndexBuilder("test").
+				Model(ModelE5Base).
+				Structured([]FieldDefinition{{Name: "title", Type: FieldTypeText}}, "title"),
+			want: func(req *CreateIndexRequest) bool {
+				return *req.Type == IndexTypeStructured && req.TensorFields[0] == "title"
+			},
+		},
+		{
+			name: "invalid settings",
+			builder: NewIndexBuilder("").
+				Model("").
+				TextPreprocessing("paragraph", 2, 0).
+				TextPreprocessing(SplitMethodWord, 0, 0).
+				TextPreprocessing(SplitMethodWord, 2, 2).
+				ImagePreprocessing("grid").
+				SpaceType("cosine").
+				HNSW(1, 16).
+				HNSW(128, 200).
+				Shards(0).
+				Replicas(-1).
+				VectorNumericType("int8").
+				Structured([]FieldDefinition{{Name: "title", Type: FieldTypeText}}, "description"),
+			wantErr: []string{
+				"model is empty",
+				`unknown split method "paragraph"`,
+				"split length must be at least 1, got 0",
+				"split overlap must be between 0 and split length 2, got 2",
+				`unknown patch method "grid"`,
+				`unknown space type "cosine"`,
+				"ef construction must be between 2 and 4096, got 1",
+				"m must be between 2 and 100, got 200",
+				"number of shards must be at least 1, got 0",
+				"number of replicas must not be negative, got -1",
+				"index name is empty",
+				`unknown vector numeric type "int8"`,
+				`tensor field "description" is not in allFields`,
+			},
+		},
+		{
+			name: "conflicting settings",
+			builder: NewIndexBuilder("test").
+				NormalizeEmbeddings(false).
+				SpaceType(SpaceTypePrenormalizedAngular).
+				ModelProperties(ModelProperties{}),
+			wantErr: []string{
+				"space type prenormalized-angular needs normalized embeddings",
+				"model properties need a model name",
+			},
+		},
+		{
+			name:    "unknown preset",
+			builder: NewIndexBuilderFromPreset("test", "tiny"),
+			wantErr: []string{`unknown index preset "tiny"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, errs := tt.builder.Build()
+			if len(errs) != len(tt.wantErr) {
+				t.Fatalf("IndexBuilder.Build() errors = %v, want %d errors", errs, len(tt.wantErr))
+			}
+			for i, err := range errs {
+				if !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), tt.wantErr[i]) {
+					t.Errorf("IndexBuilder.Build() error = %v, want %q", err, tt.wantErr[i])
+				}
+			}
+			if len(errs) > 0 {
+				if req != nil {
+					t.Errorf("IndexBuilder.Build() = %+v, want nil", req)
+				}
+				return
+			}
+			if !tt.want(req) {
+				t.Errorf("IndexBuilder.Build() = %+v", req)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -40,9 +41,11 @@ type BulkSearchResponse struct {
 //
 // The function performs the following steps:
 // 1. Validates the bulkSearchReq parameter.
-// 2. Sends a POST request to the server with the search queries in the request body.
-// 3. Checks the response status code and logs any errors.
-// 4. Returns the response from the server if the operation is successful, otherwise returns an error.
+// 2. Returns ErrUnsupportedOperation if the server is Marqo 2.x, which has no bulk search.
+// 3. Sends a POST request to the server with the search queries in the request body, with the
+// index of every query resolved if it is an alias.
+// 4. Checks the response status code and logs any errors.
+// 5. Returns the response from the server if the operation is successful, otherwise returns an error.
 //
 // Example usage:
 //
@@ -55,19 +58,54 @@ type BulkSearchResponse struct {
 //	}
 //	fmt.Printf("BulkSearchResponse: %+v\n", resp)
 func (c *Client) BulkSearch(bulkSearchReq *BulkSearchRequest) (*BulkSearchResponse, error) {
-	logger := c.logger.With("method", "BulkSearch")
+	return c.BulkSearchWithContext(context.Background(), bulkSearchReq)
+}
+
+// BulkSearchWithContext is like BulkSearch but sends the request with ctx.
+func (c *Client) BulkSearchWithContext(ctx context.Context, bulkSearchReq *BulkSearchRequest) (_ *BulkSearchResponse, err error) {
+	ctx, span := c.startSpan(ctx, "BulkSearch")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("BulkSearch")
 	for i := range bulkSearchReq.Queries {
+		c.setDefaultIndex(&bulkSearchReq.Queries[i].IndexName)
 		setDefaultSearchRequest(&bulkSearchReq.Queries[i])
 	}
-	err := validate.Struct(bulkSearchReq)
+	err = validate.Struct(bulkSearchReq)
 	if err != nil {
 		logger.Error("error validating bulk search request", "error", err)
 		return nil, err
 	}
 
+	dialect, err := c.DetectDialect(ctx)
+	if err != nil {
+		logger.Error("error detecting dialect", "error", err)
+		return nil, err
+	}
+	if dialect == DialectV2 {
+		logger.Error("bulk search is not supported by Marqo 2.x")
+		return nil, fmt.Errorf("error bulk searching: %w", ErrUnsupportedOperation)
+	}
+
+	// the index of every query is in its body, resolved if it is an alias
+	body := *bulkSearchReq
+	body.Queries = make([]SearchRequest, len(bulkSearchReq.Queries))
+	for i, query := range bulkSearchReq.Queries {
+		indexName := query.IndexName
+		if query.Index != nil {
+			indexName = *query.Index
+		}
+		indexName, err = c.resolveIndex(ctx, indexName)
+		if err != nil {
+			logger.Error("error resolving index", "error", err)
+			return nil, err
+		}
+		query.Index = &indexName
+		body.Queries[i] = query
+	}
+
 	var bulkSearchResp BulkSearchResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "BulkSearch", "", bulkSearchReq).
+		SetBody(&body).
 		SetSuccessResult(&bulkSearchResp).
 		Post(c.reqClient.BaseURL + "/indexes/_bulk_search")
 	if err != nil {
@@ -75,10 +113,13 @@ func (c *Client) BulkSearch(bulkSearchReq *BulkSearchRequest) (*BulkSearchRespon
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error bulk searching", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error bulk searching: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error bulk searching", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error bulk searching: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response bulk search: %+v", bulkSearchResp))
+	c.logResponse(ctx, logger, resp, "bulk search done",
+		"queries", len(bulkSearchReq.Queries),
+		"processing_time_ms", bulkSearchResp.ProcessingTimeMS)
 	return &bulkSearchResp, nil
 }
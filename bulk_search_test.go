@@ -0,0 +1,96 @@
+package marqo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// getMockServerForBulkSearch returns a server recording the bodies of the
+// bulk search requests
+func getMockServerForBulkSearch(mu *sync.Mutex, bodies *[]BulkSearchRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var body BulkSearchRequest
+			// disabeling lint as this is a mock server
+			// nolint
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			*bodies = append(*bodies, body)
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"result": [{"hits": []}, {"hits": []}], "processingTimeMs": 1}`))
+		}))
+}
+
+func TestClient_BulkSearch(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []BulkSearchRequest
+	)
+	mockServer := getMockServerForBulkSearch(&mu, &bodies)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	shirt, shoes := "shirt", "shoes"
+	resp, err := c.BulkSearch(&BulkSearchRequest{Queries: []SearchRequest{
+		{IndexName: "clothes", Q: &shirt},
+		{IndexName: "footwear", Q: &shoes},
+	}})
+	if err != nil {
+		t.Fatalf("Client.BulkSearch() error = %v", err)
+	}
+	if len(resp.Result) != 2 {
+		t.Errorf("Client.BulkSearch() = %+v, want 2 results", resp)
+	}
+
+	// the body used not to be sent
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || len(bodies[0].Queries) != 2 ||
+		*bodies[0].Queries[0].Q != "shirt" || *bodies[0].Queries[1].Q != "shoes" {
+		t.Fatalf("request bodies = %+v, want the 2 queries", bodies)
+	}
+	if *bodies[0].Queries[0].Index != "clothes" || *bodies[0].Queries[1].Index != "footwear" {
+		t.Errorf("request bodies = %+v, want the index of every query", bodies)
+	}
+}
+
+func TestClient_BulkSearchWithAlias(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []BulkSearchRequest
+	)
+	mockServer := getMockServerForBulkSearch(&mu, &bodies)
+	defer mockServer.Close()
+
+	store := NewMemoryAliasStore()
+	if err := store.Swap(context.Background(), "footwear", "", "footwear-v3"); err != nil {
+		t.Fatalf("Swap() error = %v", err)
+	}
+	c, err := NewClient(mockServer.URL, WithAliasStore(store))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	shirt, shoes := "shirt", "shoes"
+	if _, err := c.BulkSearch(&BulkSearchRequest{Queries: []SearchRequest{
+		{IndexName: "clothes", Q: &shirt},
+		{IndexName: "footwear", Q: &shoes},
+	}}); err != nil {
+		t.Fatalf("Client.BulkSearch() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || *bodies[0].Queries[0].Index != "clothes" || *bodies[0].Queries[1].Index != "footwear-v3" {
+		t.Errorf("request bodies = %+v, want the resolved indexes", bodies)
+	}
+}
@@ -0,0 +1,324 @@
+// Package cassette records the HTTP exchanges of a Marqo client into
+// cassette files and replays them, so that tests can run without a Marqo
+// instance.
+//
+// Record the exchanges against a live Marqo once:
+//
+//	recorder := cassette.NewRecorder("testdata/search.json", nil)
+//	client, err := marqo.NewClient("http://localhost:8882", marqo.WithTransport(recorder))
+//	// ... call the client ...
+//	err = recorder.Save()
+//
+// and replay them in the tests:
+//
+//	replayer, err := cassette.NewReplayer("testdata/search.json")
+//	client, err := marqo.NewClient("http://localhost:8882", marqo.WithTransport(replayer))
+//
+// Requests are matched on their method, path and normalized body. The
+// x-api-key and Authorization headers and the modelAuth and image download
+// headers fields of the bodies are redacted before they are saved.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the redacted header and field values
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned by the replayer when no recorded
+// interaction matches a request
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches the request")
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request
+type Request struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Options for the recorder and the replayer
+type Options func(*settings)
+
+// settings holds the redaction settings
+type settings struct {
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+// WithRedactedHeaders adds headers to redact
+func WithRedactedHeaders(headers ...string) func(*settings) {
+	return func(s *settings) {
+		for _, header := range headers {
+			s.headers[http.CanonicalHeaderKey(header)] = true
+		}
+	}
+}
+
+// WithRedactedFields adds JSON body fields to redact, at any depth
+func WithRedactedFields(fields ...string) func(*settings) {
+	return func(s *settings) {
+		for _, field := range fields {
+			s.fields[field] = true
+		}
+	}
+}
+
+// newSettings returns the default redaction settings with the options applied
+func newSettings(opt []Options) *settings {
+	s := &settings{
+		headers: map[string]bool{},
+		fields:  map[string]bool{},
+	}
+	WithRedactedHeaders("x-api-key", "Authorization")(s)
+	WithRedactedFields("modelAuth", "model_auth", "imageDownloadHeaders", "image_download_headers")(s)
+	for _, o := range opt {
+		o(s)
+	}
+	return s
+}
+
+// Recorder is an http.RoundTripper which sends the requests with the next
+// transport and records them with their responses
+type Recorder struct {
+	path     string
+	next     http.RoundTripper
+	settings *settings
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a recorder which saves the interactions to path.
+// The requests are sent with next, or http.DefaultTransport if nil.
+func NewRecorder(path string, next http.RoundTripper, opt ...Options) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{
+		path:     path,
+		next:     next,
+		settings: newSettings(opt),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(httpReq *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.next.RoundTrip(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  httpReq.Method,
+			Path:    httpReq.URL.Path,
+			Query:   httpReq.URL.RawQuery,
+			Headers: r.settings.redactHeaders(httpReq.Header),
+			Body:    r.settings.redactBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    r.settings.redactHeaders(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette file
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("error creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o600); err != nil {
+		return fmt.Errorf("error writing cassette: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an http.RoundTripper which serves the responses of a
+// cassette without sending the requests
+type Replayer struct {
+	settings *settings
+
+	mu           sync.Mutex
+	interactions []Interaction
+	// used marks the interactions already replayed
+	used []bool
+}
+
+// NewReplayer loads the cassette at path
+func NewReplayer(path string, opt ...Options) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("error decoding cassette: %w", err)
+	}
+	return &Replayer{
+		settings:     newSettings(opt),
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper. Matching interactions are
+// replayed in the order they were recorded; once they are all used, the
+// last one is replayed again.
+func (r *Replayer) RoundTrip(httpReq *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	body := r.settings.redactBody(reqBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	match := -1
+	for i, interaction := range r.interactions {
+		if interaction.Request.Method != httpReq.Method ||
+			interaction.Request.Path != httpReq.URL.Path ||
+			interaction.Request.Body != body {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, httpReq.Method, httpReq.URL.Path)
+	}
+	r.used[match] = true
+
+	recorded := r.interactions[match].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       httpReq,
+	}, nil
+}
+
+// readRequestBody returns the body of the request without consuming it
+func readRequestBody(httpReq *http.Request) ([]byte, error) {
+	if httpReq.Body == nil || httpReq.Body == http.NoBody {
+		return nil, nil
+	}
+	if httpReq.GetBody != nil {
+		body, err := httpReq.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(httpReq.Body)
+	httpReq.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// redactHeaders returns a copy of the headers with the sensitive values redacted
+func (s *settings) redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for key := range redacted {
+		if s.headers[http.CanonicalHeaderKey(key)] {
+			redacted[key] = []string{Redacted}
+		}
+	}
+	return redacted
+}
+
+// redactBody returns the normalized body with the sensitive fields
+// redacted. JSON bodies are re-encoded with sorted keys so that they match
+// regardless of the field order.
+func (s *settings) redactBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	normalized, err := json.Marshal(s.redactValue(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(normalized)
+}
+
+// redactValue redacts the sensitive fields of a decoded JSON value
+func (s *settings) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if s.fields[key] {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = s.redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.redactValue(item)
+		}
+	}
+	return value
+}
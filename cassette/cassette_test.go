@@ -0,0 +1,133 @@
+package cassette
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	marqo "github.com/ganeshdipdumbare/marqo-go"
+)
+
+// getMockServer returns a server which responds to searches and lists of
+// indexes
+func getMockServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			if strings.HasSuffix(r.URL.Path, "/search") {
+				// disabeling lint as this is a mock server
+				// nolint
+				w.Write([]byte(`{"hits": [{"_id": "1", "_score": 0.9}], "query": "shoes", "limit": 10, "offset": 0, "processingTimeMs": 5}`))
+				return
+			}
+			// nolint
+			w.Write([]byte(`{"results": [{"index_name": "products"}]}`))
+		}))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	mockServer := getMockServer()
+	path := filepath.Join(t.TempDir(), "cassettes", "search.json")
+	query := "shoes"
+	searchReq := func(secret string) *marqo.SearchRequest {
+		return &marqo.SearchRequest{
+			IndexName: "products",
+			Q:         &query,
+			ModelAuth: map[string]interface{}{"s3": map[string]interface{}{"aws_secret_access_key": secret}},
+		}
+	}
+
+	// record
+	recorder := NewRecorder(path, nil)
+	c, err := marqo.NewClient(mockServer.URL, marqo.WithTransport(recorder), marqo.WithMarqoCloudAuth("secret-key"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	recorded, err := c.Search(searchReq("secret-value"))
+	if err != nil {
+		t.Fatalf("Client.Search() error = %v", err)
+	}
+	if _, err := c.ListIndexes(); err != nil {
+		t.Fatalf("Client.ListIndexes() error = %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Recorder.Save() error = %v", err)
+	}
+	mockServer.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, secret := range []string{"secret-key", "secret-value"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q, want it redacted", secret)
+		}
+	}
+
+	// replay without the server
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+	c, err = marqo.NewClient("http://marqo.invalid:8882", marqo.WithTransport(replayer), marqo.WithMarqoCloudAuth("other-key"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	replayed, err := c.Search(searchReq("other-value"))
+	if err != nil {
+		t.Fatalf("Client.Search() error = %v", err)
+	}
+	if len(replayed.Hits) != len(recorded.Hits) || replayed.ProcessingTimeMS != recorded.ProcessingTimeMS {
+		t.Errorf("replayed search = %+v, want %+v", replayed, recorded)
+	}
+	indexes, err := c.ListIndexes()
+	if err != nil {
+		t.Fatalf("Client.ListIndexes() error = %v", err)
+	}
+	if len(indexes.Results) != 1 || indexes.Results[0].IndexName != "products" {
+		t.Errorf("replayed indexes = %+v, want products", indexes)
+	}
+
+	other := "boots"
+	_, err = c.Search(&marqo.SearchRequest{IndexName: "products", Q: &other})
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("Client.Search() error = %v, want %v", err, ErrNoInteraction)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	s := newSettings([]Options{WithRedactedFields("password")})
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "sorted keys",
+			body: `{"q": "shoes", "limit": 10}`,
+			want: `{"limit":10,"q":"shoes"}`,
+		},
+		{
+			name: "nested fields",
+			body: `{"documents": [{"_id": "1"}], "imageDownloadHeaders": {"token": "t"}, "auth": {"password": "p"}}`,
+			want: `{"auth":{"password":"REDACTED"},"documents":[{"_id":"1"}],"imageDownloadHeaders":"REDACTED"}`,
+		},
+		{
+			name: "not json",
+			body: `plain text`,
+			want: `plain text`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
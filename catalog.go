@@ -0,0 +1,150 @@
+package marqo
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultModel is the model of indexes created without a model
+const DefaultModel = ModelMiniLML6
+
+// ModelInfo describes a model supported by Marqo
+type ModelInfo struct {
+	// Name of the model
+	Name ModelName
+	// Dimensions of the vectors of the model
+	Dimensions int
+	// Text, Image, Video and Audio are the supported modalities
+	Text  bool
+	Image bool
+	Video bool
+	Audio bool
+	// SpaceType is the recommended space type
+	SpaceType SpaceType
+	// TextChunkPrefix is prepended to the text chunks of the documents
+	TextChunkPrefix string
+	// TextQueryPrefix is prepended to the text queries
+	TextQueryPrefix string
+}
+
+const (
+	e5ChunkPrefix  = "passage: "
+	e5QueryPrefix  = "query: "
+	bgeQueryPrefix = "Represent this sentence for searching relevant passages: "
+)
+
+// modelCatalog are the models supported by Marqo
+var modelCatalog = newModelCatalog([]ModelInfo{
+	{Name: ModelMiniLML6, Dimensions: 384, Text: true},
+	{Name: "hf/all_datasets_v4_MiniLM-L12", Dimensions: 384, Text: true},
+	{Name: "hf/all-MiniLM-L6-v2", Dimensions: 384, Text: true},
+	{Name: "hf/all-mpnet-base-v2", Dimensions: 768, Text: true},
+	{Name: "onnx/all_datasets_v4_MiniLM-L6", Dimensions: 384, Text: true},
+	{Name: "onnx/all-MiniLM-L6-v2", Dimensions: 384, Text: true},
+	{Name: "onnx/all-mpnet-base-v2", Dimensions: 768, Text: true},
+	{Name: "sentence-transformers/all-MiniLM-L6-v2", Dimensions: 384, Text: true},
+	{Name: "sentence-transformers/all-mpnet-base-v2", Dimensions: 768, Text: true},
+	{Name: "sentence-transformers/multi-qa-MiniLM-L6-cos-v1", Dimensions: 384, Text: true},
+	{Name: "sentence-transformers/paraphrase-multilingual-MiniLM-L12-v2", Dimensions: 384, Text: true},
+	{Name: "hf/e5-small-v2", Dimensions: 384, Text: true, TextChunkPrefix: e5ChunkPrefix, TextQueryPrefix: e5QueryPrefix},
+	{Name: ModelE5Base, Dimensions: 768, Text: true, TextChunkPrefix: e5ChunkPrefix, TextQueryPrefix: e5QueryPrefix},
+	{Name: ModelE5Large, Dimensions: 1024, Text: true, TextChunkPrefix: e5ChunkPrefix, TextQueryPrefix: e5QueryPrefix},
+	{Name: "hf/multilingual-e5-small", Dimensions: 384, Text: true, TextChunkPrefix: e5ChunkPrefix, TextQueryPrefix: e5QueryPrefix},
+	{Name: "hf/multilingual-e5-base", Dimensions: 768, Text: true, TextChunkPrefix: e5ChunkPrefix, TextQueryPrefix: e5QueryPrefix},
+	{Name: "hf/multilingual-e5-large", Dimensions: 1024, Text: true, TextChunkPrefix: e5ChunkPrefix, TextQueryPrefix: e5QueryPrefix},
+	{Name: "hf/bge-small-en-v1.5", Dimensions: 384, Text: true, TextQueryPrefix: bgeQueryPrefix},
+	{Name: "hf/bge-base-en-v1.5", Dimensions: 768, Text: true, TextQueryPrefix: bgeQueryPrefix},
+	{Name: "hf/bge-large-en-v1.5", Dimensions: 1024, Text: true, TextQueryPrefix: bgeQueryPrefix},
+	{Name: "RN50", Dimensions: 1024, Text: true, Image: true},
+	{Name: "RN101", Dimensions: 512, Text: true, Image: true},
+	{Name: "RN50x4", Dimensions: 640, Text: true, Image: true},
+	{Name: "RN50x16", Dimensions: 768, Text: true, Image: true},
+	{Name: "RN50x64", Dimensions: 1024, Text: true, Image: true},
+	{Name: "ViT-B/32", Dimensions: 512, Text: true, Image: true},
+	{Name: "ViT-B/16", Dimensions: 512, Text: true, Image: true},
+	{Name: "ViT-L/14", Dimensions: 768, Text: true, Image: true},
+	{Name: "ViT-L/14@336px", Dimensions: 768, Text: true, Image: true},
+	{Name: "open_clip/RN50/openai", Dimensions: 1024, Text: true, Image: true},
+	{Name: "open_clip/ViT-B-32/openai", Dimensions: 512, Text: true, Image: true},
+	{Name: "open_clip/ViT-B-16/openai", Dimensions: 512, Text: true, Image: true},
+	{Name: "open_clip/ViT-L-14/openai", Dimensions: 768, Text: true, Image: true},
+	{Name: ModelOpenCLIPViTB32, Dimensions: 512, Text: true, Image: true},
+	{Name: "open_clip/ViT-L-14/laion2b_s32b_b82k", Dimensions: 768, Text: true, Image: true},
+	{Name: "open_clip/ViT-H-14/laion2b_s32b_b79k", Dimensions: 1024, Text: true, Image: true},
+	{Name: "Marqo/marqo-ecommerce-embeddings-B", Dimensions: 768, Text: true, Image: true},
+	{Name: "Marqo/marqo-ecommerce-embeddings-L", Dimensions: 1024, Text: true, Image: true},
+	{Name: "LanguageBind/Video_V1.5_FT_Audio_FT_Image", Dimensions: 768, Text: true, Image: true, Video: true, Audio: true},
+	{Name: "LanguageBind/Video_V1.5_FT_Audio_FT", Dimensions: 768, Text: true, Video: true, Audio: true},
+	{Name: "LanguageBind/Video_V1.5_FT", Dimensions: 768, Text: true, Video: true},
+	{Name: "LanguageBind/Audio_FT", Dimensions: 768, Text: true, Audio: true},
+})
+
+// newModelCatalog indexes models by name. The embeddings of every model are
+// normalized, so cosine similarity is recommended unless set otherwise.
+func newModelCatalog(models []ModelInfo) map[ModelName]ModelInfo {
+	catalog := make(map[ModelName]ModelInfo, len(models))
+	for _, model := range models {
+		if model.SpaceType == "" {
+			model.SpaceType = SpaceTypeCosineSimilarity
+		}
+		catalog[model.Name] = model
+	}
+	return catalog
+}
+
+// Models returns the models of the catalog, sorted by name
+func Models() []ModelInfo {
+	models := make([]ModelInfo, 0, len(modelCatalog))
+	for _, model := range modelCatalog {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models
+}
+
+// LookupModel returns the catalog entry of the model name, and false for
+// custom models and the models missing from the catalog
+func LookupModel(name ModelName) (ModelInfo, bool) {
+	model, ok := modelCatalog[name]
+	return model, ok
+}
+
+// validateModel checks the model of createIndexReq against the catalog.
+// Custom models, set with ModelProperties, need the vector dimensions.
+// Other models missing from the catalog are left to the server to validate.
+// Settings which are valid but likely wrong, such as images with a text
+// only model or a name missing from the catalog, are returned as warnings.
+func validateModel(createIndexReq *CreateIndexRequest) ([]string, error) {
+	defaults := createIndexReq.IndexDefaults
+	if defaults == nil || defaults.Model == nil {
+		return nil, nil
+	}
+
+	model, ok := LookupModel(ModelName(*defaults.Model))
+	if !ok {
+		if defaults.ModelProperties == nil {
+			return []string{fmt.Sprintf("model %s is not in the catalog, it is validated by the server, "+
+				"custom models need model properties", *defaults.Model)}, nil
+		}
+		if defaults.ModelProperties.Dimensions == nil || *defaults.ModelProperties.Dimensions < 1 {
+			return nil, fmt.Errorf("%w: custom model %q needs the dimensions in its model properties", ErrInvalidArgument, *defaults.Model)
+		}
+		return nil, nil
+	}
+	if defaults.ModelProperties != nil {
+		return nil, nil
+	}
+
+	var warnings []string
+	if !model.Image {
+		if defaults.TreatURLsAndPointersAsImages != nil && *defaults.TreatURLsAndPointersAsImages {
+			warnings = append(warnings, fmt.Sprintf("model %s does not support images, URLs and pointers are indexed as text", model.Name))
+		}
+		for _, field := range createIndexReq.AllFields {
+			if field.Type == FieldTypeImagePointer {
+				warnings = append(warnings, fmt.Sprintf("model %s does not support images, field %s cannot be vectorised", model.Name, field.Name))
+			}
+		}
+	}
+	return warnings, nil
+}
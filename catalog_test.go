@@ -0,0 +1,154 @@
+package marqo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestModels(t *testing.T) {
+	models := Models()
+	if !sort.SliceIsSorted(models, func(i, j int) bool { return models[i].Name < models[j].Name }) {
+		t.Errorf("Models() is not sorted by name")
+	}
+	model, ok := LookupModel(DefaultModel)
+	if !ok || model.Dimensions != 384 || !model.Text || model.Image {
+		t.Errorf("LookupModel(DefaultModel) = %+v, %v", model, ok)
+	}
+	model, ok = LookupModel(ModelE5Base)
+	if !ok || model.TextChunkPrefix != "passage: " || model.TextQueryPrefix != "query: " ||
+		model.SpaceType != SpaceTypeCosineSimilarity {
+		t.Errorf("LookupModel(ModelE5Base) = %+v, %v", model, ok)
+	}
+	for _, model := range models {
+		if model.Dimensions < 1 || !(model.Text || model.Image || model.Video || model.Audio) {
+			t.Errorf("Models() has invalid model %+v", model)
+		}
+	}
+	if _, ok := LookupModel("my-model"); ok {
+		t.Errorf("LookupModel(my-model) = true, want false")
+	}
+}
+
+func Test_validateModel(t *testing.T) {
+	dimensions := 512
+	yes := true
+	model := func(name string) *string { return &name }
+
+	tests := []struct {
+		name         string
+		req          *CreateIndexRequest
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "server default model",
+			req:  &CreateIndexRequest{IndexName: "test"},
+		},
+		{
+			name: "catalog model",
+			req:  &CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{Model: model(string(ModelOpenCLIPViTB32)), TreatURLsAndPointersAsImages: &yes}},
+		},
+		{
+			name: "images with a text only model",
+			req: &CreateIndexRequest{
+				IndexName:     "test",
+				IndexDefaults: &IndexDefaults{Model: model(string(ModelE5Base)), TreatURLsAndPointersAsImages: &yes},
+				AllFields:     []FieldDefinition{{Name: "image", Type: FieldTypeImagePointer}},
+			},
+			wantWarnings: []string{
+				"model hf/e5-base-v2 does not support images, URLs and pointers are indexed as text",
+				"model hf/e5-base-v2 does not support images, field image cannot be vectorised",
+			},
+		},
+		{
+			name: "custom model",
+			req: &CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{
+				Model:           model("my-clip"),
+				ModelProperties: &ModelProperties{Name: model("ViT-B-32"), Dimensions: &dimensions, Type: model("open_clip")},
+			}},
+		},
+		{
+			name: "supported models",
+			req:  &CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{Model: model("open_clip/ViT-B-32/openai")}},
+		},
+		{
+			name: "model missing from the catalog without model properties",
+			req:  &CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{Model: model("hf/e5-bsae-v2")}},
+			wantWarnings: []string{
+				"model hf/e5-bsae-v2 is not in the catalog, it is validated by the server, custom models need model properties",
+			},
+		},
+		{
+			name: "custom model without dimensions",
+			req: &CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{
+				Model:           model("my-clip"),
+				ModelProperties: &ModelProperties{Name: model("ViT-B-32")},
+			}},
+			wantErr: `custom model "my-clip" needs the dimensions in its model properties`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := validateModel(tt.req)
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("validateModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateModel() error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.Join(warnings, "\n") != strings.Join(tt.wantWarnings, "\n") {
+				t.Errorf("validateModel() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestClient_CreateIndexWithCustomModel(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []dialectRequest
+	)
+	mockServer := getMockServerForDialect("1.5.1", &mu, &requests)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	model, modelType := "my-model", "sbert"
+	_, err = c.CreateIndex(&CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{
+		Model:           &model,
+		ModelProperties: &ModelProperties{Name: &model},
+	}})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Client.CreateIndex() error = %v, want ErrInvalidArgument", err)
+	}
+	mu.Lock()
+	if len(requests) != 0 {
+		t.Errorf("requests = %+v, want none", requests)
+	}
+	mu.Unlock()
+
+	// models missing from the catalog are validated by the server
+	model = "hf/e5-bsae-v2"
+	_, err = c.CreateIndex(&CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{Model: &model}})
+	if err != nil {
+		t.Errorf("Client.CreateIndex() error = %v", err)
+	}
+	dimensions := 768
+	_, err = c.CreateIndex(&CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{
+		Model:           &model,
+		ModelProperties: &ModelProperties{Name: &model, Dimensions: &dimensions, Type: &modelType},
+	}})
+	if err != nil {
+		t.Errorf("Client.CreateIndex() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Errorf("requests = %+v, want two", requests)
+	}
+}
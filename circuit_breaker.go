@@ -0,0 +1,323 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("marqo: circuit breaker is open")
+
+// CircuitState is the state of the circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all requests fast with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerSettings configures the circuit breaker.
+//
+// The breaker trips from closed to open after ConsecutiveFailures failures
+// in a row, or when the failure rate within the current Window reaches
+// FailureRateThreshold. After OpenTimeout it becomes half-open and lets
+// HalfOpenMaxRequests trial requests through: if they all succeed the
+// breaker closes again, a single failure opens it again.
+type CircuitBreakerSettings struct {
+	// ConsecutiveFailures trips the breaker after that many failures in a row (default: 5)
+	ConsecutiveFailures int
+	// FailureRateThreshold trips the breaker when the ratio of failed
+	// requests in the window reaches it, between 0 and 1 (default: 0, disabled)
+	FailureRateThreshold float64
+	// MinRequests is the number of requests needed in the window before the
+	// failure rate is considered (default: 20)
+	MinRequests int
+	// Window is the period over which the failure rate is counted (default: 60s)
+	Window time.Duration
+	// OpenTimeout is how long the breaker stays open before letting trial
+	// requests through (default: 30s)
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of trial requests in half-open state (default: 1)
+	HalfOpenMaxRequests int
+	// IsFailure reports whether a request failed. By default network errors
+	// and 5xx responses are failures. Requests cancelled by the caller or
+	// past the deadline of its context are not recorded.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called after every state change, e.g. to alert. The
+	// calls are serialized and in the order of the changes, a change made
+	// while a call is running is reported once it returns.
+	OnStateChange func(from, to CircuitState)
+}
+
+// WithCircuitBreaker enables the circuit breaker in front of the server.
+// Zero fields of the settings are set to their defaults.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithCircuitBreaker(marqo.CircuitBreakerSettings{
+//	        ConsecutiveFailures: 3,
+//	        OnStateChange: func(from, to marqo.CircuitState) {
+//	            log.Printf("marqo circuit breaker: %v -> %v", from, to)
+//	        },
+//	    }),
+//	)
+func WithCircuitBreaker(settings CircuitBreakerSettings) func(*Client) {
+	return func(c *Client) {
+		c.circuitBreaker = newCircuitBreaker(settings)
+	}
+}
+
+// CircuitState returns the state of the circuit breaker, CircuitClosed if
+// the breaker is not enabled
+func (c *Client) CircuitState() CircuitState {
+	if c.circuitBreaker == nil {
+		return CircuitClosed
+	}
+	cb := c.circuitBreaker
+	cb.mu.Lock()
+	state := cb.currentState(time.Now())
+	cb.mu.Unlock()
+
+	cb.notify()
+	return state
+}
+
+// circuitBreaker implements the breaker state machine
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu    sync.Mutex
+	state CircuitState
+	// openedAt is when the breaker last opened
+	openedAt time.Time
+	// consecutiveFailures is the number of failures in a row
+	consecutiveFailures int
+	// windowStart, requests and failures count the current window
+	windowStart time.Time
+	requests    int
+	failures    int
+	// halfOpenInFlight and halfOpenSuccesses count the trial requests
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+	// changes are the state changes not reported to OnStateChange yet, in
+	// order. notifyMu is held by the goroutine reporting them.
+	changes  []stateChange
+	notifyMu sync.Mutex
+}
+
+// stateChange is a change of the state of the breaker
+type stateChange struct {
+	from, to CircuitState
+}
+
+// newCircuitBreaker creates a circuit breaker with defaults applied
+func newCircuitBreaker(settings CircuitBreakerSettings) *circuitBreaker {
+	if settings.ConsecutiveFailures <= 0 {
+		settings.ConsecutiveFailures = 5
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 20
+	}
+	if settings.Window <= 0 {
+		settings.Window = 60 * time.Second
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = isCircuitFailure
+	}
+	return &circuitBreaker{
+		settings:    settings,
+		windowStart: time.Now(),
+	}
+}
+
+// isCircuitFailure is the default failure classification
+func isCircuitFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp != nil && resp.StatusCode >= http.StatusInternalServerError
+}
+
+// roundTrip is the round trip wrapper which fails fast while the breaker
+// is open and records the outcome of the requests
+func (cb *circuitBreaker) roundTrip(next req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		halfOpen, err := cb.allow()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next.RoundTrip(r)
+		if errors.Is(err, context.Canceled) || r.Context().Err() != nil {
+			// cancelled or past the deadline of the caller, says nothing
+			// about the server
+			cb.release(halfOpen)
+			return resp, err
+		}
+		var httpResp *http.Response
+		if resp != nil {
+			httpResp = resp.Response
+		}
+		cb.record(halfOpen, cb.settings.IsFailure(httpResp, err))
+		return resp, err
+	}
+}
+
+// allow reports whether a request may be sent and whether it is a trial
+// request of the half-open state
+func (cb *circuitBreaker) allow() (bool, error) {
+	cb.mu.Lock()
+	state := cb.currentState(time.Now())
+	var (
+		halfOpen bool
+		err      error
+	)
+	switch state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.halfOpenInFlight+cb.halfOpenSuccesses >= cb.settings.HalfOpenMaxRequests {
+			err = ErrCircuitOpen
+		} else {
+			cb.halfOpenInFlight++
+			halfOpen = true
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify()
+	return halfOpen, err
+}
+
+// release frees the slot of a request whose outcome is not recorded
+func (cb *circuitBreaker) release(halfOpen bool) {
+	if !halfOpen {
+		return
+	}
+	cb.mu.Lock()
+	cb.halfOpenInFlight--
+	cb.mu.Unlock()
+}
+
+// record records the outcome of a request
+func (cb *circuitBreaker) record(halfOpen bool, failure bool) {
+	cb.mu.Lock()
+	now := time.Now()
+
+	if halfOpen {
+		cb.halfOpenInFlight--
+		// a trial request which finishes after the breaker reopened is ignored
+		if cb.state == CircuitHalfOpen {
+			if failure {
+				cb.setState(CircuitOpen, now)
+			} else {
+				cb.halfOpenSuccesses++
+				if cb.halfOpenSuccesses >= cb.settings.HalfOpenMaxRequests {
+					cb.setState(CircuitClosed, now)
+				}
+			}
+		}
+	} else if cb.state == CircuitClosed {
+		if now.Sub(cb.windowStart) >= cb.settings.Window {
+			cb.windowStart = now
+			cb.requests = 0
+			cb.failures = 0
+		}
+		cb.requests++
+		if failure {
+			cb.failures++
+			cb.consecutiveFailures++
+		} else {
+			cb.consecutiveFailures = 0
+		}
+
+		rateExceeded := cb.settings.FailureRateThreshold > 0 &&
+			cb.requests >= cb.settings.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.settings.FailureRateThreshold
+		if cb.consecutiveFailures >= cb.settings.ConsecutiveFailures || rateExceeded {
+			cb.setState(CircuitOpen, now)
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify()
+}
+
+// currentState moves an open breaker to half-open once the open timeout
+// has passed and returns the state. It must be called with mu held.
+func (cb *circuitBreaker) currentState(now time.Time) CircuitState {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.settings.OpenTimeout {
+		cb.setState(CircuitHalfOpen, now)
+	}
+	return cb.state
+}
+
+// setState changes the state, resets the counters and queues the change
+// for notify. It must be called with mu held.
+func (cb *circuitBreaker) setState(state CircuitState, now time.Time) {
+	if state != cb.state && cb.settings.OnStateChange != nil {
+		cb.changes = append(cb.changes, stateChange{from: cb.state, to: state})
+	}
+	cb.state = state
+	cb.consecutiveFailures = 0
+	cb.windowStart = now
+	cb.requests = 0
+	cb.failures = 0
+	cb.halfOpenSuccesses = 0
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+}
+
+// notify reports the queued state changes to the state change callback,
+// one at a time and in order. If another goroutine is reporting them, it
+// reports the changes queued meanwhile too, which also keeps a callback
+// reading the state from deadlocking. It must be called without mu held.
+func (cb *circuitBreaker) notify() {
+	if cb.settings.OnStateChange == nil || !cb.notifyMu.TryLock() {
+		return
+	}
+	for {
+		cb.mu.Lock()
+		if len(cb.changes) == 0 {
+			// released with mu held, so that a change queued next is
+			// reported by its own goroutine
+			cb.notifyMu.Unlock()
+			cb.mu.Unlock()
+			return
+		}
+		change := cb.changes[0]
+		cb.changes = cb.changes[1:]
+		cb.mu.Unlock()
+		cb.settings.OnStateChange(change.from, change.to)
+	}
+}
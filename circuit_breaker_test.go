@@ -0,0 +1,190 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{
+			AddSource: true,
+			Level:     slog.LevelError,
+		}))
+	var (
+		healthy int32
+		hits    int32
+	)
+	mockServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			if atomic.LoadInt32(&healthy) == 0 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"numberOfDocuments": 2, "numberOfVectors": 3}`))
+		}))
+	defer mockServer.Close()
+
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+	c, err := NewClient(mockServer.URL,
+		WithLogger(logger),
+		WithCircuitBreaker(CircuitBreakerSettings{
+			ConsecutiveFailures: 2,
+			OpenTimeout:         50 * time.Millisecond,
+			OnStateChange: func(from, to CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+				transitions = append(transitions, from.String()+"->"+to.String())
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Client.Connect() error = %v", err)
+	}
+	getStats := func() error {
+		_, err := c.GetIndexStats(&GetIndexStatsRequest{IndexName: "test"})
+		return err
+	}
+
+	// two failures in a row trip the breaker
+	for i := 0; i < 2; i++ {
+		if err := getStats(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("GetIndexStats() error = %v, want server error", err)
+		}
+	}
+	if got := c.CircuitState(); got != CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want %v", got, CircuitOpen)
+	}
+
+	// the open breaker fails fast without contacting the server
+	if err := getStats(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GetIndexStats() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("server hits = %v, want 2", got)
+	}
+
+	// after the open timeout a successful trial request closes the breaker
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+	if err := getStats(); err != nil {
+		t.Fatalf("GetIndexStats() error = %v, want nil", err)
+	}
+	if got := c.CircuitState(); got != CircuitClosed {
+		t.Fatalf("CircuitState() = %v, want %v", got, CircuitClosed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreakerIgnoresCancelledRequests(t *testing.T) {
+	cb := newCircuitBreaker(CircuitBreakerSettings{ConsecutiveFailures: 1, OpenTimeout: time.Millisecond})
+	roundTrip := func(resp *req.Response, err error) error {
+		_, err = cb.roundTrip(req.RoundTripFunc(func(*req.Request) (*req.Response, error) {
+			return resp, err
+		}))(&req.Request{})
+		return err
+	}
+	state := func() CircuitState {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+		return cb.currentState(time.Now())
+	}
+
+	// a cancelled request is not a failure, nor a success
+	if err := roundTrip(nil, context.Canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("roundTrip() error = %v, want %v", err, context.Canceled)
+	}
+	if cb.requests != 0 {
+		t.Errorf("requests = %d, want 0", cb.requests)
+	}
+	// disabeling lint as the error is the failure
+	// nolint
+	roundTrip(nil, errors.New("connection refused"))
+	time.Sleep(5 * time.Millisecond)
+	if got := state(); got != CircuitHalfOpen {
+		t.Fatalf("state = %v, want %v", got, CircuitHalfOpen)
+	}
+
+	// a cancelled trial request frees its slot without closing the breaker
+	if err := roundTrip(nil, context.Canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("roundTrip() error = %v, want %v", err, context.Canceled)
+	}
+	if got := state(); got != CircuitHalfOpen {
+		t.Fatalf("state = %v, want %v", got, CircuitHalfOpen)
+	}
+	if err := roundTrip(&req.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil); err != nil {
+		t.Fatalf("roundTrip() error = %v, want nil", err)
+	}
+	if got := state(); got != CircuitClosed {
+		t.Fatalf("state = %v, want %v", got, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerIgnoresCallerDeadline(t *testing.T) {
+	cb := newCircuitBreaker(CircuitBreakerSettings{ConsecutiveFailures: 1, OpenTimeout: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := cb.roundTrip(req.RoundTripFunc(func(*req.Request) (*req.Response, error) {
+		return nil, context.DeadlineExceeded
+	}))((&req.Request{}).SetContext(ctx))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("roundTrip() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if cb.requests != 0 || cb.state != CircuitClosed {
+		t.Errorf("requests = %d, state = %v, want 0 and %v", cb.requests, cb.state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerStateChangesInOrder(t *testing.T) {
+	var (
+		cb      *circuitBreaker
+		changes []stateChange
+	)
+	cb = newCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			if len(changes) == 0 {
+				// a change made by the callback is reported after it returns
+				time.Sleep(2 * time.Millisecond)
+				cb.mu.Lock()
+				cb.currentState(time.Now())
+				cb.mu.Unlock()
+				cb.notify()
+			}
+			changes = append(changes, stateChange{from: from, to: to})
+		},
+	})
+	cb.record(false, true)
+
+	want := []stateChange{{from: CircuitClosed, to: CircuitOpen}, {from: CircuitOpen, to: CircuitHalfOpen}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
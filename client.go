@@ -3,10 +3,13 @@ package marqo
 import (
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/imroc/req/v3"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // use a single instance of Validate, it caches struct info
@@ -29,12 +32,75 @@ func WithMarqoCloudAuth(apiKey string) func(*Client) {
 	}
 }
 
+// WithHTTPClient sends the requests through the transport of the given
+// http client and uses its timeout, cookie jar and redirect policy.
+// The API key header is still added to every request.
+func WithHTTPClient(httpClient *http.Client) func(*Client) {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sends the requests through the given transport, e.g. an
+// instrumented transport or an *http.Transport with a proxy and
+// connection pool limits. The API key header is still added to every
+// request.
+func WithTransport(transport http.RoundTripper) func(*Client) {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithTimeout sets the timeout of every request, including reading the
+// response body. A shorter deadline on the request context still applies.
+func WithTimeout(timeout time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
 // Client is the client for the Marqo server
 type Client struct {
 	url       string
 	logger    *slog.Logger
 	reqClient *req.Client
 	apiKey    string // Field to hold the API key for use with MarqoCloud
+	// retryPolicy is the policy to retry failed requests, nil disables retries
+	retryPolicy *RetryPolicy
+	// circuitBreaker fails requests fast while the server is unhealthy, nil if disabled
+	circuitBreaker *circuitBreaker
+	// limiter applies the client side rate and in-flight limits
+	limiter *limiter
+	// endpointURLs, loadBalancing and healthCheck configure the endpoint
+	// pool, which is nil when the client talks to a single url
+	endpointURLs  []string
+	loadBalancing LoadBalancingStrategy
+	healthCheck   *HealthCheckSettings
+	endpoints     *endpointPool
+	// httpClient, transport and timeout customise the http client used by reqClient
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+	// tls holds the TLS options, nil if none are set
+	tls *tlsSettings
+	// tracer records a span for every operation, nil if tracing is disabled
+	tracer trace.Tracer
+	// metrics receives the metrics of every operation, nil if disabled
+	metrics MetricsRecorder
+	// middleware wraps the transport of reqClient
+	middleware []Middleware
+	// logPayloadLimit, redactedFields and operationLogLevels configure the logs
+	logPayloadLimit    int
+	redactedFields     map[string]bool
+	operationLogLevels map[string]slog.Level
+	// defaultIndex is used by the requests without an index name
+	defaultIndex string
+	// aliases resolves the index names of the requests
+	aliases AliasStore
+	// dialect is the dialect of the API, dialectDetection the state of its
+	// detection when it is DialectAuto
+	dialect          Dialect
+	dialectDetection dialectDetection
 }
 
 // NewClient creates a new client for the Marqo server.
@@ -55,8 +121,12 @@ type Client struct {
 // 1. Validates the url parameter.
 // 2. Initializes a new Client instance.
 // 3. Applies the optional parameters to the client.
-// 4. Sets the reqClient if not already set.
-// 5. Returns the new client instance if the operation is successful, otherwise returns an error.
+// 4. Sets the reqClient if not already set, applies the http client,
+// transport, timeout, TLS and middleware options and wraps its round trip with
+// the endpoint pool, the rate limits, the circuit breaker, the retry
+// policy, the metrics and the tracing.
+// 5. Starts the health probes of the endpoints if enabled.
+// 6. Returns the new client instance if the operation is successful, otherwise returns an error.
 //
 // Example usage:
 //
@@ -71,13 +141,33 @@ func NewClient(url string, opt ...Options) (*Client, error) {
 	}
 
 	client := &Client{
-		url: url,
+		url:                url,
+		dialect:            DialectV1,
+		limiter:            newLimiter(),
+		redactedFields:     map[string]bool{},
+		operationLogLevels: map[string]slog.Level{},
+	}
+	for _, field := range defaultRedactedFields {
+		client.redactedFields[field] = true
 	}
 
 	for _, o := range opt {
 		o(client)
 	}
 
+	if len(client.endpointURLs) > 0 || client.healthCheck != nil {
+		var settings HealthCheckSettings
+		if client.healthCheck != nil {
+			settings = *client.healthCheck
+		}
+		endpoints, err := newEndpointPool(url, client.endpointURLs, client.loadBalancing, settings)
+		if err != nil {
+			return nil, err
+		}
+		client.endpoints = endpoints
+		client.endpoints.probe = client.probeEndpoint
+	}
+
 	// set req client
 	if client.reqClient == nil {
 		client.reqClient = req.NewClient()
@@ -87,6 +177,14 @@ func NewClient(url string, opt ...Options) (*Client, error) {
 		if client.apiKey != "" {
 			client.reqClient.SetCommonHeader("x-api-key", client.apiKey)
 		}
+
+		client.setHTTPClient()
+		if err := client.setTLSConfig(); err != nil {
+			return nil, err
+		}
+		client.setMiddleware()
+
+		client.wrapRoundTrip()
 	}
 
 	// set default logger
@@ -96,5 +194,56 @@ func NewClient(url string, opt ...Options) (*Client, error) {
 			Level:     slog.LevelError,
 		}))
 	}
+
+	if client.endpoints != nil {
+		client.endpoints.start()
+	}
 	return client, nil
-}
\ No newline at end of file
+}
+
+// setHTTPClient applies the http client, transport and timeout options to
+// the http client of reqClient
+func (c *Client) setHTTPClient() {
+	httpClient := c.reqClient.GetClient()
+	if c.httpClient != nil {
+		if c.httpClient.Transport != nil {
+			httpClient.Transport = c.httpClient.Transport
+		}
+		if c.httpClient.Jar != nil {
+			httpClient.Jar = c.httpClient.Jar
+		}
+		if c.httpClient.CheckRedirect != nil {
+			httpClient.CheckRedirect = c.httpClient.CheckRedirect
+		}
+		if c.httpClient.Timeout > 0 {
+			c.reqClient.SetTimeout(c.httpClient.Timeout)
+		}
+	}
+	if c.transport != nil {
+		httpClient.Transport = c.transport
+	}
+	if c.timeout > 0 {
+		c.reqClient.SetTimeout(c.timeout)
+	}
+}
+
+// wrapRoundTrip wraps the round trip of the req client with the enabled
+// layers. Layers registered later wrap the earlier ones.
+func (c *Client) wrapRoundTrip() {
+	if c.endpoints != nil {
+		c.reqClient.WrapRoundTripFunc(c.endpoints.roundTrip)
+	}
+	c.reqClient.WrapRoundTripFunc(c.limiter.roundTrip)
+	if c.circuitBreaker != nil {
+		c.reqClient.WrapRoundTripFunc(c.circuitBreaker.roundTrip)
+	}
+	if c.retryPolicy != nil {
+		c.reqClient.WrapRoundTripFunc(c.retryPolicy.roundTrip)
+	}
+	if c.metrics != nil {
+		c.reqClient.WrapRoundTripFunc(c.metricsRoundTrip)
+	}
+	if c.tracer != nil {
+		c.reqClient.WrapRoundTripFunc(c.traceRoundTrip)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/imroc/req/v3"
 	"github.com/stretchr/testify/suite"
@@ -122,3 +124,84 @@ func (suite *ClientTestSuite) TestNewClient() {
 		})
 	}
 }
+
+// countingTransport counts the requests and records the API key header
+type countingTransport struct {
+	requests int32
+	apiKey   atomic.Value
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	t.apiKey.Store(r.Header.Get("x-api-key"))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func (suite *ClientTestSuite) TestNewClientWithHTTPOptions() {
+	t := suite.T()
+	tests := []struct {
+		name      string
+		transport *countingTransport
+		opt       func(*countingTransport) []Options
+	}{
+		{
+			name:      "with transport",
+			transport: &countingTransport{},
+			opt: func(transport *countingTransport) []Options {
+				return []Options{WithTransport(transport)}
+			},
+		},
+		{
+			name:      "with http client",
+			transport: &countingTransport{},
+			opt: func(transport *countingTransport) []Options {
+				return []Options{WithHTTPClient(&http.Client{Transport: transport})}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := append(tt.opt(tt.transport), WithLogger(suite.Logger), WithMarqoCloudAuth("secret"))
+			c, err := NewClient(suite.MockServer.URL, opt...)
+			if err != nil {
+				t.Errorf("NewClient() error = %v", err)
+				return
+			}
+			if _, err := c.GetModels(); err != nil {
+				t.Errorf("Client.GetModels() error = %v", err)
+				return
+			}
+			if got := atomic.LoadInt32(&tt.transport.requests); got != 1 {
+				t.Errorf("transport requests = %v, want 1", got)
+			}
+			if got := tt.transport.apiKey.Load(); got != "secret" {
+				t.Errorf("x-api-key header = %v, want secret", got)
+			}
+		})
+	}
+}
+
+func (suite *ClientTestSuite) TestNewClientWithTimeout() {
+	t := suite.T()
+	slowServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		}))
+	defer slowServer.Close()
+
+	c, err := NewClient(slowServer.URL, WithLogger(suite.Logger), WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Errorf("NewClient() error = %v", err)
+		return
+	}
+	start := time.Now()
+	if _, err := c.GetModels(); err == nil {
+		t.Errorf("Client.GetModels() error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Client.GetModels() took %v, want it to time out", elapsed)
+	}
+}
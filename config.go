@@ -0,0 +1,378 @@
+package marqo
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by ConfigFromEnv and NewClientFromEnv
+const (
+	EnvURL                 = "MARQO_URL"
+	EnvAPIKey              = "MARQO_API_KEY"
+	EnvTimeout             = "MARQO_TIMEOUT"
+	EnvRetryMaxAttempts    = "MARQO_RETRY_MAX_ATTEMPTS"
+	EnvRetryInitialBackoff = "MARQO_RETRY_INITIAL_BACKOFF"
+	EnvRetryMaxBackoff     = "MARQO_RETRY_MAX_BACKOFF"
+	EnvTLSCAFile           = "MARQO_TLS_CA_FILE"
+	EnvTLSCertFile         = "MARQO_TLS_CERT_FILE"
+	EnvTLSKeyFile          = "MARQO_TLS_KEY_FILE"
+	EnvTLSMinVersion       = "MARQO_TLS_MIN_VERSION"
+	EnvTLSSPKIPins         = "MARQO_TLS_SPKI_PINS"
+	EnvLogLevel            = "MARQO_LOG_LEVEL"
+	EnvDefaultIndex        = "MARQO_DEFAULT_INDEX"
+	EnvDialect             = "MARQO_DIALECT"
+)
+
+// Config is the configuration of a client, loaded from a YAML or JSON file
+// with LoadConfig or from the environment with ConfigFromEnv
+type Config struct {
+	// URLs are the Marqo endpoints, the first one is the primary endpoint
+	URLs []string `json:"urls" yaml:"urls" validate:"required,min=1,dive,required,url"`
+	// APIKey is the Marqo Cloud API key
+	APIKey string `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	// Timeout is the timeout of every request, e.g. "30s"
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" validate:"min=0"`
+	// Retry enables retries of the failed requests
+	Retry *RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// TLS holds the TLS files and settings
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// LogLevel is the level of the default logger, one of debug, info, warn and error
+	LogLevel string `json:"log_level,omitempty" yaml:"log_level,omitempty" validate:"omitempty,oneof=debug info warn error"`
+	// DefaultIndex is the index used by requests without an index name
+	DefaultIndex string `json:"default_index,omitempty" yaml:"default_index,omitempty"`
+	// Dialect is the API dialect, one of v1, v2 and auto (default: v1, set
+	// v2 or auto with Marqo 2.x), see WithDialect
+	Dialect string `json:"dialect,omitempty" yaml:"dialect,omitempty" validate:"omitempty,oneof=v1 v2 auto"`
+}
+
+// RetryConfig is the retry configuration of a client, zero fields keep the
+// defaults of DefaultRetryPolicy
+type RetryConfig struct {
+	MaxAttempts    int      `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty" validate:"min=0"`
+	InitialBackoff Duration `json:"initial_backoff,omitempty" yaml:"initial_backoff,omitempty" validate:"min=0"`
+	MaxBackoff     Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty" validate:"min=0"`
+}
+
+// TLSConfig is the TLS configuration of a client
+type TLSConfig struct {
+	// CAFile is the PEM file of the certificate authorities of the server
+	CAFile string `json:"ca_file,omitempty" yaml:"ca_file,omitempty" validate:"omitempty,file"`
+	// CertFile and KeyFile are the PEM files of the client certificate
+	CertFile string `json:"cert_file,omitempty" yaml:"cert_file,omitempty" validate:"required_with=KeyFile,omitempty,file"`
+	KeyFile  string `json:"key_file,omitempty" yaml:"key_file,omitempty" validate:"required_with=CertFile,omitempty,file"`
+	// MinVersion is the minimum TLS version, "1.2" or "1.3"
+	MinVersion string `json:"min_version,omitempty" yaml:"min_version,omitempty" validate:"omitempty,oneof=1.2 1.3"`
+	// SPKIPins are the pinned public keys, see WithSPKIPins
+	SPKIPins []string `json:"spki_pins,omitempty" yaml:"spki_pins,omitempty" validate:"dive,base64"`
+}
+
+// Duration is a time.Duration read from strings such as "1.5s" in YAML and
+// JSON files
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s: want a string such as \"30s\"", data)
+	}
+	return d.parse(s)
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return fmt.Errorf("invalid duration %q: want a string such as \"30s\"", value.Value)
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// parse sets the duration from a string such as "30s"
+func (d *Duration) parse(s string) error {
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// LoadConfig reads the client configuration from a YAML or JSON file,
+// selected by the file extension (.yaml, .yml or .json). Unknown fields are
+// rejected. The URLs and the API key are read from MARQO_URL and
+// MARQO_API_KEY when the file does not set them.
+//
+// Example config.yaml:
+//
+//	urls:
+//	  - https://marqo-1.internal:8882
+//	  - https://marqo-2.internal:8882
+//	timeout: 30s
+//	retry:
+//	  max_attempts: 5
+//	tls:
+//	  ca_file: /etc/marqo/ca.pem
+//	log_level: info
+//	default_index: products
+//	dialect: v2
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+
+	var config Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q: want .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding config %s: %w", path, err)
+	}
+
+	if len(config.URLs) == 0 {
+		config.URLs = splitList(os.Getenv(EnvURL))
+	}
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv(EnvAPIKey)
+	}
+	return &config, nil
+}
+
+// ConfigFromEnv reads the client configuration from the environment:
+//
+//	MARQO_URL                    comma separated list of endpoints (required)
+//	MARQO_API_KEY                Marqo Cloud API key
+//	MARQO_TIMEOUT                request timeout, e.g. "30s"
+//	MARQO_RETRY_MAX_ATTEMPTS     enables retries with that many attempts
+//	MARQO_RETRY_INITIAL_BACKOFF  e.g. "100ms"
+//	MARQO_RETRY_MAX_BACKOFF      e.g. "5s"
+//	MARQO_TLS_CA_FILE            PEM file of the server certificate authorities
+//	MARQO_TLS_CERT_FILE          PEM file of the client certificate
+//	MARQO_TLS_KEY_FILE           PEM file of the client certificate key
+//	MARQO_TLS_MIN_VERSION        "1.2" or "1.3"
+//	MARQO_TLS_SPKI_PINS          comma separated list of pinned public keys, see WithSPKIPins
+//	MARQO_LOG_LEVEL              debug, info, warn or error
+//	MARQO_DEFAULT_INDEX          index used by requests without an index name
+//	MARQO_DIALECT                v1, v2 or auto, see WithDialect
+func ConfigFromEnv() (*Config, error) {
+	config := &Config{
+		URLs:         splitList(os.Getenv(EnvURL)),
+		APIKey:       os.Getenv(EnvAPIKey),
+		LogLevel:     strings.ToLower(os.Getenv(EnvLogLevel)),
+		DefaultIndex: os.Getenv(EnvDefaultIndex),
+		Dialect:      strings.ToLower(os.Getenv(EnvDialect)),
+	}
+
+	var err error
+	if config.Timeout, err = durationFromEnv(EnvTimeout); err != nil {
+		return nil, err
+	}
+
+	retry := &RetryConfig{}
+	if s := os.Getenv(EnvRetryMaxAttempts); s != "" {
+		if retry.MaxAttempts, err = strconv.Atoi(s); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EnvRetryMaxAttempts, s, err)
+		}
+	}
+	if retry.InitialBackoff, err = durationFromEnv(EnvRetryInitialBackoff); err != nil {
+		return nil, err
+	}
+	if retry.MaxBackoff, err = durationFromEnv(EnvRetryMaxBackoff); err != nil {
+		return nil, err
+	}
+	if *retry != (RetryConfig{}) {
+		config.Retry = retry
+	}
+
+	tlsConfig := &TLSConfig{
+		CAFile:     os.Getenv(EnvTLSCAFile),
+		CertFile:   os.Getenv(EnvTLSCertFile),
+		KeyFile:    os.Getenv(EnvTLSKeyFile),
+		MinVersion: os.Getenv(EnvTLSMinVersion),
+		SPKIPins:   splitList(os.Getenv(EnvTLSSPKIPins)),
+	}
+	if tlsConfig.CAFile != "" || tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" || tlsConfig.MinVersion != "" ||
+		len(tlsConfig.SPKIPins) > 0 {
+		config.TLS = tlsConfig
+	}
+	return config, nil
+}
+
+// NewClientFromEnv creates a new client configured from the environment,
+// see ConfigFromEnv. The options are applied after the configuration.
+//
+// Example usage:
+//
+//	// MARQO_URL=http://localhost:8882 MARQO_TIMEOUT=10s
+//	client, err := marqo.NewClientFromEnv()
+//	if err != nil {
+//	    log.Fatalf("Failed to create client: %v", err)
+//	}
+func NewClientFromEnv(opt ...Options) (*Client, error) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(config, opt...)
+}
+
+// NewClientFromConfig creates a new client from the configuration.
+//
+// Parameters:
+//
+//	config (*Config): The configuration of the client.
+//	opt (...Options): Optional parameters for the client, applied after the configuration.
+//
+// Returns:
+//
+//	*Client: A new Marqo client instance.
+//	error: An error if the configuration is invalid, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Validates the config parameter.
+// 2. Converts the configuration to client options.
+// 3. Creates the client with NewClient.
+//
+// Example usage:
+//
+//	config, err := marqo.LoadConfig("config.yaml")
+//	if err != nil {
+//	    log.Fatalf("Failed to load config: %v", err)
+//	}
+//	client, err := marqo.NewClientFromConfig(config)
+func NewClientFromConfig(config *Config, opt ...Options) (*Client, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	err := validate.Struct(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	var configOpt []Options
+	if len(config.URLs) > 1 {
+		configOpt = append(configOpt, WithEndpoints(config.URLs[1:]...))
+	}
+	if config.APIKey != "" {
+		configOpt = append(configOpt, WithMarqoCloudAuth(config.APIKey))
+	}
+	if config.Timeout > 0 {
+		configOpt = append(configOpt, WithTimeout(time.Duration(config.Timeout)))
+	}
+	if config.Retry != nil {
+		configOpt = append(configOpt, WithRetryPolicy(RetryPolicy{
+			MaxAttempts:    config.Retry.MaxAttempts,
+			InitialBackoff: time.Duration(config.Retry.InitialBackoff),
+			MaxBackoff:     time.Duration(config.Retry.MaxBackoff),
+		}))
+	}
+	if config.TLS != nil {
+		if config.TLS.CAFile != "" {
+			configOpt = append(configOpt, WithCAFile(config.TLS.CAFile))
+		}
+		if config.TLS.CertFile != "" {
+			configOpt = append(configOpt, WithClientCertificateFiles(config.TLS.CertFile, config.TLS.KeyFile))
+		}
+		switch config.TLS.MinVersion {
+		case "1.2":
+			configOpt = append(configOpt, WithMinTLSVersion(tls.VersionTLS12))
+		case "1.3":
+			configOpt = append(configOpt, WithMinTLSVersion(tls.VersionTLS13))
+		}
+		if len(config.TLS.SPKIPins) > 0 {
+			configOpt = append(configOpt, WithSPKIPins(config.TLS.SPKIPins...))
+		}
+	}
+	if config.LogLevel != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		configOpt = append(configOpt, WithLogger(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			AddSource: true,
+			Level:     level,
+		}))))
+	}
+	if config.DefaultIndex != "" {
+		configOpt = append(configOpt, WithDefaultIndex(config.DefaultIndex))
+	}
+	if config.Dialect != "" {
+		dialect, err := ParseDialect(config.Dialect)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		configOpt = append(configOpt, WithDialect(dialect))
+	}
+
+	return NewClient(config.URLs[0], append(configOpt, opt...)...)
+}
+
+// WithDefaultIndex sets the index used by the document, search and index
+// information requests which have no index name. CreateIndex and
+// DeleteIndex always need an explicit index name.
+func WithDefaultIndex(indexName string) func(*Client) {
+	return func(c *Client) {
+		c.defaultIndex = indexName
+	}
+}
+
+// setDefaultIndex sets indexName to the default index if it is empty
+func (c *Client) setDefaultIndex(indexName *string) {
+	if *indexName == "" {
+		*indexName = c.defaultIndex
+	}
+}
+
+// durationFromEnv parses the duration in the environment variable, zero if unset
+func durationFromEnv(key string) (Duration, error) {
+	s := os.Getenv(key)
+	if s == "" {
+		return 0, nil
+	}
+	var d Duration
+	if err := d.parse(s); err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// splitList splits a comma separated list, such as urls
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,235 @@
+package marqo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	_, caPEM, _, err := generateClientCertificate(1)
+	if err != nil {
+		t.Fatalf("generateClientCertificate() error = %v", err)
+	}
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		env      map[string]string
+		want     func(*Config) bool
+		wantErr  bool
+		wantLoad bool
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+urls:
+  - http://marqo-1:8882
+  - http://marqo-2:8882
+api_key: key
+timeout: 30s
+retry:
+  max_attempts: 5
+  initial_backoff: 200ms
+tls:
+  ca_file: ` + caFile + `
+  min_version: "1.3"
+log_level: info
+default_index: products
+dialect: v2
+`,
+			want: func(c *Config) bool {
+				return len(c.URLs) == 2 && c.APIKey == "key" &&
+					time.Duration(c.Timeout) == 30*time.Second &&
+					c.Retry.MaxAttempts == 5 && time.Duration(c.Retry.InitialBackoff) == 200*time.Millisecond &&
+					c.TLS.CAFile == caFile && c.TLS.MinVersion == "1.3" &&
+					c.LogLevel == "info" && c.DefaultIndex == "products" && c.Dialect == "v2"
+			},
+		},
+		{
+			name:    "json with url and api key from env",
+			file:    "config.json",
+			content: `{"timeout": "1m", "default_index": "products"}`,
+			env:     map[string]string{EnvURL: "http://marqo-1:8882, http://marqo-2:8882", EnvAPIKey: "env-key"},
+			want: func(c *Config) bool {
+				return len(c.URLs) == 2 && c.URLs[1] == "http://marqo-2:8882" && c.APIKey == "env-key" &&
+					time.Duration(c.Timeout) == time.Minute
+			},
+		},
+		{
+			name:     "unknown field",
+			file:     "config.yaml",
+			content:  "urls: [http://marqo:8882]\nunknown: true\n",
+			wantLoad: true,
+		},
+		{
+			name:     "invalid duration",
+			file:     "config.json",
+			content:  `{"urls": ["http://marqo:8882"], "timeout": "soon"}`,
+			wantLoad: true,
+		},
+		{
+			name:     "unsupported extension",
+			file:     "config.toml",
+			content:  `urls = ["http://marqo:8882"]`,
+			wantLoad: true,
+		},
+		{
+			name:    "missing url",
+			file:    "config.yaml",
+			content: "timeout: 1s\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid log level",
+			file:    "config.yaml",
+			content: "urls: [http://marqo:8882]\nlog_level: verbose\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid dialect",
+			file:    "config.yaml",
+			content: "urls: [http://marqo:8882]\ndialect: v3\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing ca file",
+			file:    "config.yaml",
+			content: "urls: [http://marqo:8882]\ntls:\n  ca_file: /missing/ca.pem\n",
+			wantErr: true,
+		},
+		{
+			name:    "cert file without key file",
+			file:    "config.yaml",
+			content: "urls: [http://marqo:8882]\ntls:\n  cert_file: " + caFile + "\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvURL, "")
+			t.Setenv(EnvAPIKey, "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			config, err := LoadConfig(path)
+			if (err != nil) != tt.wantLoad {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantLoad)
+			}
+			if err != nil {
+				return
+			}
+			if tt.want != nil && !tt.want(config) {
+				t.Errorf("LoadConfig() = %+v", config)
+			}
+			_, err = NewClientFromConfig(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewClientFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	var hits int32
+	mockServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&hits, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.Header.Get("x-api-key") != "env-key" || !strings.HasPrefix(r.URL.Path, "/indexes/products/") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"numberOfDocuments": 3}`))
+		}))
+	defer mockServer.Close()
+
+	t.Setenv(EnvURL, mockServer.URL)
+	t.Setenv(EnvAPIKey, "env-key")
+	t.Setenv(EnvTimeout, "5s")
+	t.Setenv(EnvRetryMaxAttempts, "2")
+	t.Setenv(EnvRetryInitialBackoff, "1ms")
+	t.Setenv(EnvLogLevel, "ERROR")
+	t.Setenv(EnvDefaultIndex, "products")
+
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() error = %v", err)
+	}
+	stats, err := c.GetIndexStats(&GetIndexStatsRequest{})
+	if err != nil {
+		t.Fatalf("Client.GetIndexStats() error = %v", err)
+	}
+	if stats.NumberOfDocuments != 3 {
+		t.Errorf("Client.GetIndexStats() = %+v, want 3 documents", stats)
+	}
+
+	t.Run("spki pins", func(t *testing.T) {
+		t.Setenv(EnvTLSSPKIPins, "cGluLWE=, cGluLWI=")
+		config, err := ConfigFromEnv()
+		if err != nil {
+			t.Fatalf("ConfigFromEnv() error = %v", err)
+		}
+		if config.TLS == nil || !reflect.DeepEqual(config.TLS.SPKIPins, []string{"cGluLWE=", "cGluLWI="}) {
+			t.Errorf("ConfigFromEnv() TLS = %+v, want the pins", config.TLS)
+		}
+	})
+
+	t.Run("dialect", func(t *testing.T) {
+		t.Setenv(EnvDialect, "V2")
+		c, err := NewClientFromEnv()
+		if err != nil {
+			t.Fatalf("NewClientFromEnv() error = %v", err)
+		}
+		dialect, err := c.DetectDialect(context.Background())
+		if err != nil || dialect != DialectV2 {
+			t.Errorf("Client.DetectDialect() = %v, %v, want v2", dialect, err)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "missing url", key: EnvURL, value: ""},
+		{name: "invalid url", key: EnvURL, value: "not a url"},
+		{name: "invalid timeout", key: EnvTimeout, value: "5"},
+		{name: "invalid max attempts", key: EnvRetryMaxAttempts, value: "many"},
+		{name: "invalid tls version", key: EnvTLSMinVersion, value: "1.0"},
+		{name: "invalid spki pin", key: EnvTLSSPKIPins, value: "not a pin"},
+		{name: "invalid dialect", key: EnvDialect, value: "v3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := NewClientFromEnv(); err == nil {
+				t.Errorf("NewClientFromEnv() error = nil, want error for %s=%q", tt.key, tt.value)
+			}
+		})
+	}
+}
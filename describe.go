@@ -0,0 +1,195 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// IndexDescription is the report of an index returned by DescribeIndexes
+type IndexDescription struct {
+	IndexName string
+	// IndexStatus is the Marqo Cloud status of the index, if any
+	IndexStatus string
+	// NumberOfDocuments and NumberOfVectors are zero if the stats failed
+	NumberOfDocuments int
+	NumberOfVectors   int
+	// HealthStatus is the status of the index, empty if the health failed
+	HealthStatus string
+	// StorageIsAvailable is whether the backend storage of the index is
+	// available, false if the health failed
+	StorageIsAvailable bool
+	// Model is the model of the index, empty if the settings failed
+	Model string
+	// Stats, Health and Settings are the responses of the index, nil if
+	// the request failed
+	Stats    *GetIndexStatsResponse
+	Health   *GetIndexHealthResponse
+	Settings *GetIndexSettingsResponse
+	// Err joins the errors of the requests of the index, nil if they all
+	// succeeded
+	Err error
+}
+
+// describeSettings are the settings of DescribeIndexes
+type describeSettings struct {
+	concurrency int
+	indexNames  []string
+}
+
+// DescribeOption configures DescribeIndexes
+type DescribeOption func(*describeSettings)
+
+// WithDescribeConcurrency sets how many requests DescribeIndexes sends at
+// once (default: 8)
+func WithDescribeConcurrency(concurrency int) DescribeOption {
+	return func(s *describeSettings) {
+		s.concurrency = concurrency
+	}
+}
+
+// WithDescribeIndexNames describes the indexes instead of every listed one
+func WithDescribeIndexNames(indexNames ...string) DescribeOption {
+	return func(s *describeSettings) {
+		s.indexNames = indexNames
+	}
+}
+
+// DescribeIndexes reports the stats, health and settings of every index.
+//
+// This method gets the stats, health and settings of the indexes
+// concurrently. The failures of an index are reported in its Err, the call
+// only fails if the indexes cannot be listed. The listed indexes are
+// described as is, the names of WithDescribeIndexNames may be aliases.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context of the requests.
+//	opts (...DescribeOption): The concurrency and the indexes to describe.
+//
+// Returns:
+//
+//	[]IndexDescription: The reports of the indexes, sorted by name.
+//	error: An error if the indexes cannot be listed, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Lists the indexes, unless WithDescribeIndexNames is set.
+// 2. Sends the stats, health and settings requests of every index, at most the
+// concurrency at once.
+// 3. Combines the responses of every index, joining its errors.
+//
+// Example usage:
+//
+//	descriptions, err := client.DescribeIndexes(ctx, marqo.WithDescribeConcurrency(4))
+//	if err != nil {
+//	    log.Fatalf("Failed to list indexes: %v", err)
+//	}
+//	for _, d := range descriptions {
+//	    if d.Err != nil {
+//	        log.Printf("%s: %v", d.IndexName, d.Err)
+//	    }
+//	    fmt.Printf("%s: %d documents, %s, %s\n", d.IndexName, d.NumberOfDocuments, d.HealthStatus, d.Model)
+//	}
+func (c *Client) DescribeIndexes(ctx context.Context, opts ...DescribeOption) (_ []IndexDescription, err error) {
+	ctx, span := c.startSpan(ctx, "DescribeIndexes")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("DescribeIndexes")
+	s := &describeSettings{concurrency: 8}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = 1
+	}
+
+	var descriptions []IndexDescription
+	if s.indexNames != nil {
+		for _, indexName := range s.indexNames {
+			descriptions = append(descriptions, IndexDescription{IndexName: indexName})
+		}
+	} else {
+		listIndexesResp, err := c.ListIndexesWithContext(ctx)
+		if err != nil {
+			logger.Error("error listing indexes", "error", err)
+			return nil, err
+		}
+		for _, result := range listIndexesResp.Results {
+			descriptions = append(descriptions, IndexDescription{IndexName: result.IndexName, IndexStatus: result.IndexStatus})
+		}
+	}
+	sort.Slice(descriptions, func(i, j int) bool {
+		return descriptions[i].IndexName < descriptions[j].IndexName
+	})
+	// the listed indexes are physical, even if an alias shadows them
+	describeCtx := ctx
+	if s.indexNames == nil {
+		describeCtx = withPhysicalIndex(ctx)
+	}
+
+	// every index has three requests, which write distinct fields
+	errs := make([][3]error, len(descriptions))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+	run := func(index, slot int, request string, fn func(d *IndexDescription) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[index][slot] = fmt.Errorf("error getting index %s: %w", request, ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+			errs[index][slot] = fn(&descriptions[index])
+		}()
+	}
+	for i := range descriptions {
+		indexName := descriptions[i].IndexName
+		run(i, 0, "stats", func(d *IndexDescription) error {
+			stats, err := c.GetIndexStatsWithContext(describeCtx, &GetIndexStatsRequest{IndexName: indexName})
+			if err != nil {
+				return err
+			}
+			d.Stats = stats
+			d.NumberOfDocuments = stats.NumberOfDocuments
+			d.NumberOfVectors = stats.NumberOfVectors
+			return nil
+		})
+		run(i, 1, "health", func(d *IndexDescription) error {
+			health, err := c.GetIndexHealthWithContext(describeCtx, &GetIndexHealthRequest{IndexName: indexName})
+			if err != nil {
+				return err
+			}
+			d.Health = health
+			d.HealthStatus = health.Status
+			d.StorageIsAvailable = health.Backend.StorageIsAvailable
+			return nil
+		})
+		run(i, 2, "settings", func(d *IndexDescription) error {
+			settings, err := c.GetIndexSettingsWithContext(describeCtx, &GetIndexSettingsRequest{IndexName: indexName})
+			if err != nil {
+				return err
+			}
+			d.Settings = settings
+			if settings.IndexDefaults != nil && settings.IndexDefaults.Model != nil {
+				d.Model = *settings.IndexDefaults.Model
+			}
+			return nil
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for i := range descriptions {
+		descriptions[i].Err = errors.Join(errs[i][:]...)
+		if descriptions[i].Err != nil {
+			failed++
+			logger.Warn("error describing index", "index", descriptions[i].IndexName, "error", descriptions[i].Err)
+		}
+	}
+	logger.Info("indexes described", "indexes", len(descriptions), "failed", failed)
+	return descriptions, nil
+}
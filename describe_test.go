@@ -0,0 +1,127 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// getMockServerForDescribe returns a server listing the indexes a, b and c,
+// where the health of b fails and c does not exist, and recording the
+// maximum number of concurrent requests
+func getMockServerForDescribe(inFlight, maxInFlight *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(inFlight, 1)
+			defer atomic.AddInt32(inFlight, -1)
+			for {
+				max := atomic.LoadInt32(maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			var resp string
+			switch {
+			case r.URL.Path == "/indexes":
+				resp = `{"results": [{"index_name": "c"}, {"index_name": "b"}, {"index_name": "a"}]}`
+			case strings.HasPrefix(r.URL.Path, "/indexes/c/"):
+				w.WriteHeader(http.StatusNotFound)
+				// disabeling lint as this is a mock server
+				// nolint
+				w.Write([]byte(`{"message": "index not found", "code": "index_not_found"}`))
+				return
+			case r.URL.Path == "/indexes/b/health":
+				w.WriteHeader(http.StatusInternalServerError)
+				// disabeling lint as this is a mock server
+				// nolint
+				w.Write([]byte(`{"message": "internal error", "code": "internal"}`))
+				return
+			case strings.HasSuffix(r.URL.Path, "/stats"):
+				resp = `{"numberOfDocuments": 2, "numberOfVectors": 3}`
+			case strings.HasSuffix(r.URL.Path, "/health"):
+				resp = `{"status": "green", "backend": {"status": "green", "storage_is_available": true}}`
+			case strings.HasSuffix(r.URL.Path, "/settings"):
+				resp = `{"index_defaults": {"model": "hf/e5-base-v2"}, "number_of_shards": 1}`
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(resp))
+		}))
+}
+
+func TestClient_DescribeIndexes(t *testing.T) {
+	var inFlight, maxInFlight int32
+	mockServer := getMockServerForDescribe(&inFlight, &maxInFlight)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	descriptions, err := c.DescribeIndexes(context.Background(), WithDescribeConcurrency(2))
+	if err != nil {
+		t.Fatalf("Client.DescribeIndexes() error = %v", err)
+	}
+	if len(descriptions) != 3 {
+		t.Fatalf("Client.DescribeIndexes() = %+v, want 3 indexes", descriptions)
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max > 2 {
+		t.Errorf("max concurrent requests = %d, want at most 2", max)
+	}
+
+	a, b, missing := descriptions[0], descriptions[1], descriptions[2]
+	if a.IndexName != "a" || a.Err != nil || a.NumberOfDocuments != 2 || a.NumberOfVectors != 3 ||
+		a.HealthStatus != "green" || !a.StorageIsAvailable || a.Model != "hf/e5-base-v2" || a.Settings == nil {
+		t.Errorf("description of a = %+v", a)
+	}
+	if b.IndexName != "b" || b.Health != nil || b.HealthStatus != "" || b.NumberOfDocuments != 2 || b.Model == "" ||
+		b.Err == nil || !strings.Contains(b.Err.Error(), "health") {
+		t.Errorf("description of b = %+v", b)
+	}
+	if missing.IndexName != "c" || !errors.Is(missing.Err, ErrIndexNotFound) ||
+		strings.Count(missing.Err.Error(), "error getting index") != 3 {
+		t.Errorf("description of c = %+v", missing)
+	}
+
+	descriptions, err = c.DescribeIndexes(context.Background(), WithDescribeIndexNames("a"))
+	if err != nil || len(descriptions) != 1 || descriptions[0].Err != nil {
+		t.Errorf("Client.DescribeIndexes() = %+v, %v, want a", descriptions, err)
+	}
+}
+
+func TestClient_DescribeIndexesShadowedByAlias(t *testing.T) {
+	var inFlight, maxInFlight int32
+	mockServer := getMockServerForDescribe(&inFlight, &maxInFlight)
+	defer mockServer.Close()
+
+	store := NewMemoryAliasStore()
+	c, err := NewClient(mockServer.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithAliasStore(store))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	// the alias a shadows the index a, and points to the missing index c
+	if err := c.SetAlias(context.Background(), "a", "c"); err != nil {
+		t.Fatalf("SetAlias() error = %v", err)
+	}
+	descriptions, err := c.DescribeIndexes(context.Background())
+	if err != nil || len(descriptions) != 3 {
+		t.Fatalf("Client.DescribeIndexes() = %+v, %v, want 3 indexes", descriptions, err)
+	}
+	if a := descriptions[0]; a.IndexName != "a" || a.Err != nil || a.NumberOfDocuments != 2 || a.HealthStatus != "green" {
+		t.Errorf("description of a = %+v, want the physical index a", a)
+	}
+
+	// the names passed by the caller are resolved
+	descriptions, err = c.DescribeIndexes(context.Background(), WithDescribeIndexNames("a"))
+	if err != nil || len(descriptions) != 1 || !errors.Is(descriptions[0].Err, ErrIndexNotFound) {
+		t.Errorf("Client.DescribeIndexes() = %+v, %v, want the missing index c", descriptions, err)
+	}
+}
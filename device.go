@@ -1,6 +1,9 @@
 package marqo
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // GetCPUInfoResponse is the response from the server containing CPU information.
 type GetCPUInfoResponse struct {
@@ -31,11 +34,17 @@ type GetCPUInfoResponse struct {
 //	}
 //	fmt.Printf("GetCPUInfoResponse: %+v\n", resp)
 func (c *Client) GetCPUInfo() (*GetCPUInfoResponse, error) {
-	logger := c.logger.With("method", "GetCPUInfo")
+	return c.GetCPUInfoWithContext(context.Background())
+}
+
+// GetCPUInfoWithContext is like GetCPUInfo but sends the request with ctx.
+func (c *Client) GetCPUInfoWithContext(ctx context.Context) (_ *GetCPUInfoResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetCPUInfo")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetCPUInfo")
 	var result GetCPUInfoResponse
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetCPUInfo", "", nil).
 		SetSuccessResult(&result).
 		Get(c.reqClient.BaseURL + "/device/cpu")
 	if err != nil {
@@ -43,11 +52,12 @@ func (c *Client) GetCPUInfo() (*GetCPUInfoResponse, error) {
 		return nil, err
 	}
 	if resp.Response.StatusCode != 200 {
-		logger.Error("error getting CPU info", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting CPU info: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting CPU info", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting CPU info: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response CPU info: %+v", result))
+	c.logResponse(ctx, logger, resp, "got CPU info")
 	return &result, nil
 }
 
@@ -59,11 +69,17 @@ type GetCUDAInfoResponse struct {
 // GetCUDAInfo returns the CUDA info from the server.
 // Returns the response from the server and an error if the operation fails.
 func (c *Client) GetCUDAInfo() (*GetCUDAInfoResponse, error) {
-	logger := c.logger.With("method", "GetCUDAInfo")
+	return c.GetCUDAInfoWithContext(context.Background())
+}
+
+// GetCUDAInfoWithContext is like GetCUDAInfo but sends the request with ctx.
+func (c *Client) GetCUDAInfoWithContext(ctx context.Context) (_ *GetCUDAInfoResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetCUDAInfo")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetCUDAInfo")
 	var result GetCUDAInfoResponse
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetCUDAInfo", "", nil).
 		SetSuccessResult(&result).
 		Get(c.reqClient.BaseURL + "/device/cuda")
 	if err != nil {
@@ -71,11 +87,13 @@ func (c *Client) GetCUDAInfo() (*GetCUDAInfoResponse, error) {
 		return nil, err
 	}
 	if resp.Response.StatusCode != 200 {
-		logger.Error("error getting CUDA info", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting CUDA info: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting CUDA info", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting CUDA info: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response CUDA info: %+v", result))
+	c.logResponse(ctx, logger, resp, "got CUDA info",
+		"cuda_devices", len(result.CUDADevices))
 	return &result, nil
 }
 
@@ -0,0 +1,362 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedOperation is returned when the server version does not
+// support the operation, e.g. BulkSearch on Marqo 2.x
+var ErrUnsupportedOperation = errors.New("marqo: operation not supported by the server version")
+
+// Dialect is the wire format of the Marqo API, which changed between
+// Marqo 1.x and 2.x
+type Dialect int
+
+const (
+	// DialectV1 is the API of Marqo 1.x, with snake_case index_defaults. It
+	// is the zero value and the default, even with a Marqo 2.x server.
+	DialectV1 Dialect = iota
+	// DialectV2 is the API of Marqo 2.x, with camelCase top level index
+	// settings and without bulk search and shards
+	DialectV2
+	// DialectAuto detects the dialect from the server version on the first
+	// request which depends on it
+	DialectAuto
+)
+
+// dialectRetryInterval is how long the detection error is returned without
+// probing the server again after the detection failed
+const dialectRetryInterval = 30 * time.Second
+
+// String returns the name of the dialect
+func (d Dialect) String() string {
+	switch d {
+	case DialectV1:
+		return "v1"
+	case DialectV2:
+		return "v2"
+	case DialectAuto:
+		return "auto"
+	default:
+		return "Dialect(" + strconv.Itoa(int(d)) + ")"
+	}
+}
+
+// ParseDialect returns the dialect named s, one of "v1", "v2" and "auto"
+func ParseDialect(s string) (Dialect, error) {
+	switch s {
+	case "v1":
+		return DialectV1, nil
+	case "v2":
+		return DialectV2, nil
+	case "auto":
+		return DialectAuto, nil
+	default:
+		return DialectV1, fmt.Errorf("%w: unknown dialect %q, want v1, v2 or auto", ErrInvalidArgument, s)
+	}
+}
+
+// WithDialect sets the dialect of the Marqo API (default: DialectV1). The
+// server version is not detected by default, so clients of Marqo 2.x must
+// set DialectV2 or DialectAuto, otherwise they send Marqo 1.x requests.
+// DialectAuto detects it from the server version with a GET request to the
+// root endpoint, sent before the first request which depends on it. The
+// requests which depend on the dialect fail while it cannot be detected.
+func WithDialect(dialect Dialect) func(*Client) {
+	return func(c *Client) {
+		c.dialect = dialect
+	}
+}
+
+// dialectDetection is the state of the dialect detection of a client
+type dialectDetection struct {
+	mu sync.Mutex
+	// dialect is the detected dialect, valid once detected is true
+	dialect  Dialect
+	detected bool
+	// probe is closed when the probe in flight, if any, completes
+	probe chan struct{}
+	// err is the error of the last probe, retried after failedAt plus
+	// dialectRetryInterval
+	err      error
+	failedAt time.Time
+}
+
+// DetectDialect returns the dialect used by the client. With DialectAuto,
+// it is detected from the version reported by the server root endpoint and
+// cached. Servers whose root endpoint is not found or reports no version
+// are Marqo 1.x. After a failed detection, the error is returned without
+// probing the server again for 30s.
+func (c *Client) DetectDialect(ctx context.Context) (Dialect, error) {
+	if c.dialect != DialectAuto {
+		return c.dialect, nil
+	}
+	d := &c.dialectDetection
+	for {
+		d.mu.Lock()
+		switch {
+		case d.detected:
+			d.mu.Unlock()
+			return d.dialect, nil
+		case d.err != nil && time.Since(d.failedAt) < dialectRetryInterval:
+			err := d.err
+			d.mu.Unlock()
+			return DialectAuto, err
+		case d.probe != nil:
+			// wait for the probe in flight
+			probe := d.probe
+			d.mu.Unlock()
+			select {
+			case <-probe:
+				continue
+			case <-ctx.Done():
+				return DialectAuto, ctx.Err()
+			}
+		}
+		probe := make(chan struct{})
+		d.probe = probe
+		d.mu.Unlock()
+
+		dialect, err := c.probeDialect(ctx)
+
+		d.mu.Lock()
+		d.probe = nil
+		// a cancelled probe says nothing about the server
+		if err != nil && ctx.Err() == nil {
+			d.err = err
+			d.failedAt = time.Now()
+		}
+		if err == nil {
+			d.dialect = dialect
+			d.detected = true
+			d.err = nil
+		}
+		d.mu.Unlock()
+		close(probe)
+		return dialect, err
+	}
+}
+
+// probeDialect gets the dialect from the server version
+func (c *Client) probeDialect(ctx context.Context) (Dialect, error) {
+	getVersionResp, err := c.GetVersionWithContext(ctx)
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		// old servers have no root endpoint
+		return DialectV1, nil
+	case err != nil:
+		return DialectAuto, fmt.Errorf("error detecting the server dialect: %w", err)
+	case getVersionResp.Version == "":
+		return DialectV1, nil
+	}
+	major, err := getVersionResp.MajorVersion()
+	if err != nil {
+		return DialectAuto, fmt.Errorf("error detecting the server dialect: %w", err)
+	}
+	if major >= 2 {
+		return DialectV2, nil
+	}
+	return DialectV1, nil
+}
+
+// createIndexRequestV2 is the body of CreateIndexRequest for Marqo 2.x
+type createIndexRequestV2 struct {
+	Type                         string                `json:"type,omitempty"`
+	Model                        *string               `json:"model,omitempty"`
+	ModelProperties              *ModelProperties      `json:"modelProperties,omitempty"`
+	NormalizeEmbeddings          *bool                 `json:"normalizeEmbeddings,omitempty"`
+	TreatURLsAndPointersAsImages *bool                 `json:"treatUrlsAndPointersAsImages,omitempty"`
+	TextPreprocessing            *textPreprocessingV2  `json:"textPreprocessing,omitempty"`
+	ImagePreprocessing           *imagePreprocessingV2 `json:"imagePreprocessing,omitempty"`
+	ANNParameters                *annParametersV2      `json:"annParameters,omitempty"`
+	AllFields                    []FieldDefinition     `json:"allFields,omitempty"`
+	TensorFields                 []string              `json:"tensorFields,omitempty"`
+	VectorNumericType            *string               `json:"vectorNumericType,omitempty"`
+}
+
+type textPreprocessingV2 struct {
+	SplitLength  *int    `json:"splitLength,omitempty"`
+	SplitOverlap *int    `json:"splitOverlap,omitempty"`
+	SplitMethod  *string `json:"splitMethod,omitempty"`
+}
+
+type imagePreprocessingV2 struct {
+	PatchMethod *string `json:"patchMethod,omitempty"`
+}
+
+type annParametersV2 struct {
+	SpaceType  *string                 `json:"spaceType,omitempty"`
+	Parameters *hnswMethodParametersV2 `json:"parameters,omitempty"`
+}
+
+type hnswMethodParametersV2 struct {
+	EFConstruction *int `json:"efConstruction,omitempty"`
+	M              *int `json:"m,omitempty"`
+}
+
+// spaceTypesV2 maps the Marqo 1.x space types to the Marqo 2.x ones
+var spaceTypesV2 = map[string]string{
+	"cosinesimil": "angular",
+	"l2":          "euclidean",
+}
+
+// newCreateIndexRequestV2 converts createIndexReq to the Marqo 2.x body.
+// The shards and replicas are not part of the Marqo 2.x API and are dropped.
+func newCreateIndexRequestV2(createIndexReq *CreateIndexRequest) *createIndexRequestV2 {
+	body := &createIndexRequestV2{
+		Type:              IndexTypeUnstructured,
+		AllFields:         createIndexReq.AllFields,
+		TensorFields:      createIndexReq.TensorFields,
+		VectorNumericType: createIndexReq.VectorNumericType,
+	}
+	if createIndexReq.Type != nil {
+		body.Type = *createIndexReq.Type
+	}
+	defaults := createIndexReq.IndexDefaults
+	if defaults == nil {
+		return body
+	}
+	body.Model = defaults.Model
+	body.ModelProperties = defaults.ModelProperties
+	body.NormalizeEmbeddings = defaults.NormalizeEmbeddings
+	// structured indexes declare image fields with the image_pointer type
+	if body.Type != IndexTypeStructured {
+		body.TreatURLsAndPointersAsImages = defaults.TreatURLsAndPointersAsImages
+	}
+	if defaults.TextPreprocessing != nil {
+		body.TextPreprocessing = &textPreprocessingV2{
+			SplitLength:  defaults.TextPreprocessing.SplitLength,
+			SplitOverlap: defaults.TextPreprocessing.SplitOverlap,
+			SplitMethod:  defaults.TextPreprocessing.SplitMethod,
+		}
+	}
+	if defaults.ImagePreprocessing != nil {
+		body.ImagePreprocessing = &imagePreprocessingV2{
+			PatchMethod: defaults.ImagePreprocessing.PatchMethod,
+		}
+	}
+	if defaults.ANNParameters != nil {
+		body.ANNParameters = &annParametersV2{
+			SpaceType: spaceTypeV2(defaults.ANNParameters.SpaceType, defaults.NormalizeEmbeddings),
+		}
+		if defaults.ANNParameters.Parameters != nil {
+			body.ANNParameters.Parameters = &hnswMethodParametersV2{
+				EFConstruction: defaults.ANNParameters.Parameters.EFConstruction,
+				M:              defaults.ANNParameters.Parameters.M,
+			}
+		}
+	}
+	return body
+}
+
+// spaceTypeV2 returns the Marqo 2.x space type of a Marqo 1.x one. Cosine
+// similarity of normalized embeddings is "prenormalized-angular". Space
+// types without an equivalent are sent unchanged.
+func spaceTypeV2(spaceType *string, normalizeEmbeddings *bool) *string {
+	if spaceType == nil {
+		return nil
+	}
+	converted, ok := spaceTypesV2[*spaceType]
+	if !ok {
+		return spaceType
+	}
+	if *spaceType == "cosinesimil" && (normalizeEmbeddings == nil || *normalizeEmbeddings) {
+		converted = "prenormalized-angular"
+	}
+	return &converted
+}
+
+// searchRequestV2 is the body of SearchRequest for Marqo 2.x, which takes
+// every parameter in the body
+type searchRequestV2 struct {
+	Q                    *string                `json:"q,omitempty"`
+	Limit                *int                   `json:"limit,omitempty"`
+	Offset               *int                   `json:"offset,omitempty"`
+	Filter               *string                `json:"filter,omitempty"`
+	SearchableAttributes []string               `json:"searchableAttributes,omitempty"`
+	ShowHighlights       *bool                  `json:"showHighlights,omitempty"`
+	SearchMethod         *string                `json:"searchMethod,omitempty"`
+	AttributesToRetrieve []string               `json:"attributesToRetrieve,omitempty"`
+	ReRanker             *string                `json:"reRanker,omitempty"`
+	Boost                map[string][2]float64  `json:"boost,omitempty"`
+	ImageDownloadHeaders map[string]interface{} `json:"imageDownloadHeaders,omitempty"`
+	Context              *Context               `json:"context,omitempty"`
+	ScoreModifiers       *ScoreModifiers        `json:"scoreModifiers,omitempty"`
+	ModelAuth            map[string]interface{} `json:"modelAuth,omitempty"`
+	TextQueryPrefix      *string                `json:"textQueryPrefix,omitempty"`
+	HybridParameters     *HybridParameters      `json:"hybridParameters,omitempty"`
+}
+
+// newSearchRequestV2 converts searchReq to the Marqo 2.x body and query
+// parameters
+func newSearchRequestV2(searchReq *SearchRequest) (*searchRequestV2, map[string]string) {
+	body := &searchRequestV2{
+		Q:                    searchReq.Q,
+		Limit:                searchReq.Limit,
+		Offset:               searchReq.Offset,
+		Filter:               searchReq.Filter,
+		SearchableAttributes: searchReq.SearchableAttributes,
+		ShowHighlights:       searchReq.ShowHighlights,
+		SearchMethod:         searchReq.SearchMethod,
+		AttributesToRetrieve: searchReq.AttributesToRetrieve,
+		ReRanker:             searchReq.ReRanker,
+		Boost:                searchReq.Boost,
+		ImageDownloadHeaders: searchReq.ImageDownloadHeaders,
+		Context:              searchReq.Context,
+		ScoreModifiers:       searchReq.ScoreModifiers,
+		ModelAuth:            searchReq.ModelAuth,
+		TextQueryPrefix:      searchReq.TextQueryPrefix,
+	}
+	if searchReq.SearchMethod != nil && *searchReq.SearchMethod == "HYBRID" {
+		body.HybridParameters = searchReq.HybridParameters
+	}
+	queryParams := map[string]string{}
+	if searchReq.Device != nil {
+		queryParams["device"] = *searchReq.Device
+	}
+	if searchReq.Telemetry != nil {
+		queryParams["telemetry"] = strconv.FormatBool(*searchReq.Telemetry)
+	}
+	return body, queryParams
+}
+
+// upsertDocumentsRequestV2 is the body of UpsertDocumentsRequest for Marqo 2.x
+type upsertDocumentsRequestV2 struct {
+	Documents            []interface{}          `json:"documents"`
+	TensorFields         []string               `json:"tensorFields,omitempty"`
+	UseExistingTensors   *bool                  `json:"useExistingTensors,omitempty"`
+	ImageDownloadHeaders map[string]string      `json:"imageDownloadHeaders,omitempty"`
+	Mappings             map[string]interface{} `json:"mappings,omitempty"`
+	ModelAuth            map[string]interface{} `json:"modelAuth,omitempty"`
+	TextChunkPrefix      *string                `json:"textChunkPrefix,omitempty"`
+}
+
+// newUpsertDocumentsRequestV2 converts upsertDocumentsReq to the Marqo 2.x
+// body and query parameters. Marqo 2.x has no refresh parameter and the
+// client batch size is only used by the python client.
+func newUpsertDocumentsRequestV2(upsertDocumentsReq *UpsertDocumentsRequest) (*upsertDocumentsRequestV2, map[string]string) {
+	body := &upsertDocumentsRequestV2{
+		Documents:            upsertDocumentsReq.Documents,
+		TensorFields:         upsertDocumentsReq.TensorFields,
+		UseExistingTensors:   upsertDocumentsReq.UseExistingTensors,
+		ImageDownloadHeaders: upsertDocumentsReq.ImageDownloadHeaders,
+		Mappings:             upsertDocumentsReq.Mappings,
+		ModelAuth:            upsertDocumentsReq.ModelAuth,
+		TextChunkPrefix:      upsertDocumentsReq.TextChunkPrefix,
+	}
+	queryParams := map[string]string{}
+	if upsertDocumentsReq.Device != nil {
+		queryParams["device"] = *upsertDocumentsReq.Device
+	}
+	if upsertDocumentsReq.Telemetry != nil {
+		queryParams["telemetry"] = strconv.FormatBool(*upsertDocumentsReq.Telemetry)
+	}
+	return body, queryParams
+}
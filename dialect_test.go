@@ -0,0 +1,395 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// dialectRequest is a request received by the dialect mock server
+type dialectRequest struct {
+	path  string
+	query string
+	body  string
+}
+
+// getMockServerForDialect returns a server which reports version at the
+// root endpoint, or 404 if version is empty, and records the other requests
+func getMockServerForDialect(version string, mu *sync.Mutex, requests *[]dialectRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			*requests = append(*requests, dialectRequest{path: r.URL.Path, query: r.URL.RawQuery, body: string(body)})
+			mu.Unlock()
+
+			var resp string
+			switch {
+			case r.URL.Path == "/" && version == "":
+				w.WriteHeader(http.StatusNotFound)
+				return
+			case r.URL.Path == "/":
+				resp = `{"message": "Welcome to Marqo", "version": "` + version + `"}`
+			case strings.HasSuffix(r.URL.Path, "/settings"):
+				resp = `{"type": "unstructured", "model": "hf/e5-base-v2", "normalizeEmbeddings": true,
+					"textPreprocessing": {"splitLength": 2, "splitOverlap": 0, "splitMethod": "sentence"},
+					"annParameters": {"spaceType": "prenormalized-angular", "parameters": {"efConstruction": 512, "m": 16}}}`
+			default:
+				resp = `{"acknowledged": true, "hits": [], "items": [], "result": []}`
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(resp))
+		}))
+}
+
+func TestDialect(t *testing.T) {
+	model := "hf/e5-base-v2"
+	spaceType := "cosinesimil"
+	shards := 3
+	query := "shirt"
+	limit := 5
+	refresh := true
+	vectorNumericType := VectorNumericTypeBfloat16
+
+	tests := []struct {
+		name        string
+		version     string
+		opt         []Options
+		call        func(c *Client) error
+		wantDialect Dialect
+		wantErr     error
+		want        func(requests []dialectRequest) bool
+	}{
+		{
+			name:        "v2 create index",
+			version:     "2.5.0",
+			wantDialect: DialectV2,
+			call: func(c *Client) error {
+				_, err := c.CreateIndex(&CreateIndexRequest{
+					IndexName: "test",
+					IndexDefaults: &IndexDefaults{
+						Model:         &model,
+						ANNParameters: &ANNParameters{SpaceType: &spaceType},
+					},
+					NumberOfShards: &shards,
+				})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				body := requests[len(requests)-1].body
+				return strings.Contains(body, `"type":"unstructured"`) &&
+					strings.Contains(body, `"model":"hf/e5-base-v2"`) &&
+					strings.Contains(body, `"annParameters":{"spaceType":"prenormalized-angular"`) &&
+					!strings.Contains(body, "index_defaults") &&
+					!strings.Contains(body, "number_of_shards")
+			},
+		},
+		{
+			name:        "v2 search",
+			version:     "2.5.0",
+			wantDialect: DialectV2,
+			call: func(c *Client) error {
+				_, err := c.Search(&SearchRequest{
+					IndexName:            "test",
+					Q:                    &query,
+					Limit:                &limit,
+					ImageDownloadHeaders: map[string]interface{}{"x-token": "token"},
+				})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				last := requests[len(requests)-1]
+				return last.query == "" &&
+					strings.Contains(last.body, `"limit":5`) &&
+					strings.Contains(last.body, `"imageDownloadHeaders":{"x-token":"token"}`)
+			},
+		},
+		{
+			name:        "v2 upsert documents",
+			version:     "2.5.0",
+			wantDialect: DialectV2,
+			call: func(c *Client) error {
+				_, err := c.UpsertDocuments(&UpsertDocumentsRequest{
+					IndexName:    "test",
+					Documents:    []interface{}{map[string]interface{}{"_id": "1"}},
+					TensorFields: []string{"text"},
+					Refresh:      &refresh,
+				})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				last := requests[len(requests)-1]
+				return !strings.Contains(last.query, "refresh") &&
+					strings.Contains(last.body, `"tensorFields":["text"]`)
+			},
+		},
+		{
+			name:        "v2 bulk search is not supported",
+			version:     "2.5.0",
+			wantDialect: DialectV2,
+			wantErr:     ErrUnsupportedOperation,
+			call: func(c *Client) error {
+				_, err := c.BulkSearch(&BulkSearchRequest{
+					Queries: []SearchRequest{{IndexName: "test", Q: &query}},
+				})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				return len(requests) == 1
+			},
+		},
+		{
+			name:        "v2 index settings",
+			version:     "2.5.0",
+			wantDialect: DialectV2,
+			call: func(c *Client) error {
+				resp, err := c.GetIndexSettings(&GetIndexSettingsRequest{IndexName: "test"})
+				if err != nil {
+					return err
+				}
+				defaults := resp.IndexDefaults
+				if defaults == nil || *defaults.Model != model || *defaults.TextPreprocessing.SplitLength != 2 ||
+					*defaults.ANNParameters.SpaceType != "prenormalized-angular" || *defaults.ANNParameters.Parameters.M != 16 {
+					return errors.New("unexpected index settings")
+				}
+				return nil
+			},
+		},
+		{
+			name:        "v1 create index",
+			version:     "1.5.1",
+			wantDialect: DialectV1,
+			call: func(c *Client) error {
+				_, err := c.CreateIndex(&CreateIndexRequest{
+					IndexName:         "test",
+					IndexDefaults:     &IndexDefaults{Model: &model},
+					NumberOfShards:    &shards,
+					VectorNumericType: &vectorNumericType,
+				})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				body := requests[len(requests)-1].body
+				return strings.Contains(body, `"index_defaults":{`) &&
+					strings.Contains(body, `"model":"hf/e5-base-v2"`) &&
+					strings.Contains(body, `"number_of_shards":3`) &&
+					!strings.Contains(body, "bfloat16")
+			},
+		},
+		{
+			name:        "server without version is v1",
+			wantDialect: DialectV1,
+			call: func(c *Client) error {
+				_, err := c.Search(&SearchRequest{IndexName: "test", Q: &query, Limit: &limit})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				return strings.Contains(requests[len(requests)-1].query, "limit=5")
+			},
+		},
+		{
+			name:        "pinned dialect",
+			version:     "1.5.1",
+			opt:         []Options{WithDialect(DialectV2)},
+			wantDialect: DialectV2,
+			call: func(c *Client) error {
+				_, err := c.Search(&SearchRequest{IndexName: "test", Q: &query})
+				return err
+			},
+			want: func(requests []dialectRequest) bool {
+				return len(requests) == 1 && requests[0].path != "/"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests []dialectRequest
+			)
+			mockServer := getMockServerForDialect(tt.version, &mu, &requests)
+			defer mockServer.Close()
+
+			c, err := NewClient(mockServer.URL, append([]Options{WithDialect(DialectAuto)}, tt.opt...)...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			err = tt.call(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("call error = %v, wantErr %v", err, tt.wantErr)
+			}
+			dialect, err := c.DetectDialect(context.Background())
+			if err != nil {
+				t.Fatalf("Client.DetectDialect() error = %v", err)
+			}
+			if dialect != tt.wantDialect {
+				t.Errorf("Client.DetectDialect() = %v, want %v", dialect, tt.wantDialect)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.want != nil && !tt.want(requests) {
+				t.Errorf("requests = %+v", requests)
+			}
+		})
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []dialectRequest
+	)
+	mockServer := getMockServerForDialect("2.11.3", &mu, &requests)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	resp, err := c.GetVersion()
+	if err != nil {
+		t.Fatalf("Client.GetVersion() error = %v", err)
+	}
+	major, err := resp.MajorVersion()
+	if err != nil || major != 2 || resp.Version != "2.11.3" {
+		t.Errorf("Client.GetVersion() = %+v, major = %d, error = %v", resp, major, err)
+	}
+
+	if _, err := (&GetVersionResponse{Version: "latest"}).MajorVersion(); err == nil {
+		t.Errorf("GetVersionResponse.MajorVersion() error = nil, want error")
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	for _, dialect := range []Dialect{DialectV1, DialectV2, DialectAuto} {
+		got, err := ParseDialect(dialect.String())
+		if err != nil || got != dialect {
+			t.Errorf("ParseDialect(%q) = %v, %v, want %v", dialect.String(), got, err, dialect)
+		}
+	}
+	if _, err := ParseDialect("v3"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ParseDialect(\"v3\") error = %v, want ErrInvalidArgument", err)
+	}
+	var zero Dialect
+	if zero != DialectV1 {
+		t.Errorf("zero Dialect = %v, want v1", zero)
+	}
+}
+
+func TestDetectDialect(t *testing.T) {
+	t.Run("v1 by default without probe", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			requests []dialectRequest
+		)
+		mockServer := getMockServerForDialect("2.11.3", &mu, &requests)
+		defer mockServer.Close()
+
+		c, err := NewClient(mockServer.URL)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		dialect, err := c.DetectDialect(context.Background())
+		if err != nil || dialect != DialectV1 || len(requests) != 0 {
+			t.Errorf("Client.DetectDialect() = %v, %v, requests = %+v", dialect, err, requests)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantDialect Dialect
+		wantErr     bool
+		wantProbes  int32
+	}{
+		{
+			name:        "no root endpoint is v1",
+			status:      http.StatusNotFound,
+			body:        `{"message": "not found", "code": "not_found"}`,
+			wantDialect: DialectV1,
+			wantProbes:  1,
+		},
+		{
+			name:        "no version is v1",
+			status:      http.StatusOK,
+			body:        `{"message": "Welcome to Marqo"}`,
+			wantDialect: DialectV1,
+			wantProbes:  1,
+		},
+		{
+			name:        "auth error is not v1 and backs off",
+			status:      http.StatusUnauthorized,
+			body:        `{"message": "invalid api key", "code": "unauthorized"}`,
+			wantDialect: DialectAuto,
+			wantErr:     true,
+			wantProbes:  1,
+		},
+		{
+			name:        "server error backs off",
+			status:      http.StatusInternalServerError,
+			body:        `{"message": "internal error", "code": "internal"}`,
+			wantDialect: DialectAuto,
+			wantErr:     true,
+			wantProbes:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var probes int32
+			mockServer := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					atomic.AddInt32(&probes, 1)
+					time.Sleep(5 * time.Millisecond)
+					w.WriteHeader(tt.status)
+					// disabeling lint as this is a mock server
+					// nolint
+					w.Write([]byte(tt.body))
+				}))
+			defer mockServer.Close()
+
+			c, err := NewClient(mockServer.URL, WithDialect(DialectAuto), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					dialect, err := c.DetectDialect(context.Background())
+					if dialect != tt.wantDialect || (err != nil) != tt.wantErr ||
+						(tt.status == http.StatusUnauthorized && !errors.Is(err, ErrUnauthorized)) {
+						t.Errorf("Client.DetectDialect() = %v, %v, want %v, %v", dialect, err, tt.wantDialect, tt.wantErr)
+					}
+				}()
+			}
+			wg.Wait()
+			if got := atomic.LoadInt32(&probes); got != tt.wantProbes {
+				t.Errorf("probes = %d, want %d", got, tt.wantProbes)
+			}
+			if tt.wantErr {
+				// the requests which depend on the dialect fail instead of
+				// guessing it, without probing the server again
+				query := "shirt"
+				_, err := c.Search(&SearchRequest{IndexName: "test", Q: &query})
+				if err == nil || !strings.Contains(err.Error(), "error detecting the server dialect") {
+					t.Errorf("Client.Search() error = %v, want the dialect detection error", err)
+				}
+				if got := atomic.LoadInt32(&probes); got != tt.wantProbes {
+					t.Errorf("probes after search = %d, want %d", got, tt.wantProbes)
+				}
+			}
+		})
+	}
+}
@@ -57,6 +57,49 @@ The simplest way to use Marqo is to create a new client and use its methods to i
 	  fmt.Printf("SearchResponse: %+v\n", searchResp)
 	}
 
+# Context
+
+Every Client method has a WithContext variant (SearchWithContext,
+UpsertDocumentsWithContext, ...) that takes a context.Context as its first
+argument. The context is attached to the underlying HTTP request, so
+cancelling it aborts the in-flight request and a deadline on it acts as a
+per-request timeout:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	searchResp, err := client.SearchWithContext(ctx, searchReq)
+
+The variants without a context use context.Background().
+
+# Errors
+
+When the server responds with an error status the returned error wraps an
+*APIError holding the HTTP status, the Marqo error code, type, message and
+the raw body. Common failures can be matched with errors.Is:
+
+	_, err := client.Search(searchReq)
+	switch {
+	case errors.Is(err, marqo.ErrIndexNotFound):
+	    // the index is missing
+	case errors.Is(err, marqo.ErrInvalidArgument):
+	    // e.g. bad filter syntax
+	}
+
+# Logging
+
+The client logs through the *slog.Logger set with WithLogger. Each method
+logs counts and timings at Info level, e.g. the number of hits of a
+search, and the request and response payloads at Debug level. Payloads
+are truncated (see WithLogPayloadLimit) and the modelAuth and image
+download headers fields are redacted, as are the fields added with
+WithRedactedFields. WithOperationLogLevel changes the level of a single
+method:
+
+	client, err := marqo.NewClient("http://localhost:8882",
+	    marqo.WithLogger(logger),
+	    marqo.WithOperationLogLevel("Search", slog.LevelDebug),
+	)
+
 For a full guide visit https://github.com/ganeshdipdumbare/marqo-go
 
 # License
@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -54,7 +55,8 @@ type Item struct {
 //
 // The function performs the following steps:
 // 1. Validates the upsertDocumentsReq parameter.
-// 2. Sends a POST request to the server with the documents in the request body.
+// 2. Sends a POST request to the server with the documents in the request body,
+// encoded for the dialect of the server.
 // 3. Checks the response status code and logs any errors.
 // 4. Returns the response from the server if the operation is successful, otherwise returns an error.
 //
@@ -70,12 +72,25 @@ type Item struct {
 //	}
 //	fmt.Printf("UpsertDocumentsResponse: %+v\n", resp)
 func (c *Client) UpsertDocuments(upsertDocumentsReq *UpsertDocumentsRequest) (*UpsertDocumentsResponse, error) {
-	logger := c.logger.With("method", "UpsertDocuments")
-	err := validate.Struct(upsertDocumentsReq)
+	return c.UpsertDocumentsWithContext(context.Background(), upsertDocumentsReq)
+}
+
+// UpsertDocumentsWithContext is like UpsertDocuments but sends the request with ctx.
+func (c *Client) UpsertDocumentsWithContext(ctx context.Context, upsertDocumentsReq *UpsertDocumentsRequest) (_ *UpsertDocumentsResponse, err error) {
+	ctx, span := c.startSpan(ctx, "UpsertDocuments")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("UpsertDocuments")
+	c.setDefaultIndex(&upsertDocumentsReq.IndexName)
+	err = validate.Struct(upsertDocumentsReq)
 	if err != nil {
 		logger.Error("error validating upsert documents request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, upsertDocumentsReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var upsertDocumentsResp UpsertDocumentsResponse
 	queryParams := map[string]string{}
@@ -89,22 +104,36 @@ func (c *Client) UpsertDocuments(upsertDocumentsReq *UpsertDocumentsRequest) (*U
 		queryParams["telemetry"] = strconv.FormatBool(*upsertDocumentsReq.Telemetry)
 	}
 
-	resp, err := c.reqClient.
-		R().
+	dialect, err := c.DetectDialect(ctx)
+	if err != nil {
+		logger.Error("error detecting dialect", "error", err)
+		return nil, err
+	}
+	var body interface{} = upsertDocumentsReq
+	if dialect == DialectV2 {
+		body, queryParams = newUpsertDocumentsRequestV2(upsertDocumentsReq)
+	}
+
+	resp, err := c.newRequest(ctx, "UpsertDocuments", indexName, upsertDocumentsReq).
 		SetQueryParams(queryParams).
-		SetBody(upsertDocumentsReq).
+		SetBody(body).
 		SetSuccessResult(&upsertDocumentsResp).
-		Post(c.reqClient.BaseURL + "/indexes/" + upsertDocumentsReq.IndexName + "/documents")
+		Post(c.reqClient.BaseURL + "/indexes/" + indexName + "/documents")
 	if err != nil {
 		logger.Error("error upserting documents", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error upserting documents", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error upserting documents: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error upserting documents", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error upserting documents: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response upsert documents: %+v", upsertDocumentsResp))
+	c.logResponse(ctx, logger, resp, "documents upserted",
+		"index", indexName,
+		"documents", len(upsertDocumentsResp.Items),
+		"errors", upsertDocumentsResp.Errors,
+		"processing_time_ms", upsertDocumentsResp.ProcessingTimeMS)
 	return &upsertDocumentsResp, nil
 }
 
@@ -172,29 +201,44 @@ type DeletedItem struct {
 //	}
 //	fmt.Printf("DeleteDocumentsResponse: %+v\n", resp)
 func (c *Client) DeleteDocuments(deleteDocumentsReq *DeleteDocumentsRequest) (*DeleteDocumentsResponse, error) {
-	logger := c.logger.With("method", "DeleteDocuments")
-	err := validate.Struct(deleteDocumentsReq)
+	return c.DeleteDocumentsWithContext(context.Background(), deleteDocumentsReq)
+}
+
+// DeleteDocumentsWithContext is like DeleteDocuments but sends the request with ctx.
+func (c *Client) DeleteDocumentsWithContext(ctx context.Context, deleteDocumentsReq *DeleteDocumentsRequest) (_ *DeleteDocumentsResponse, err error) {
+	ctx, span := c.startSpan(ctx, "DeleteDocuments")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("DeleteDocuments")
+	c.setDefaultIndex(&deleteDocumentsReq.IndexName)
+	err = validate.Struct(deleteDocumentsReq)
 	if err != nil {
 		logger.Error("error validating delete documents request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, deleteDocumentsReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var deleteDocumentsResp DeleteDocumentsResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "DeleteDocuments", indexName, deleteDocumentsReq).
 		SetBody(deleteDocumentsReq.DocumentIDs).
 		SetSuccessResult(&deleteDocumentsResp).
-		Post(c.reqClient.BaseURL + "/indexes/" + deleteDocumentsReq.IndexName + "/documents/delete-batch")
+		Post(c.reqClient.BaseURL + "/indexes/" + indexName + "/documents/delete-batch")
 	if err != nil {
 		logger.Error("error deleting documents", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error deleting documents", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error deleting documents: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error deleting documents", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error deleting documents: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response delete documents: %+v", deleteDocumentsResp))
+	c.logResponse(ctx, logger, resp, "documents deleted",
+		"index", indexName,
+		"documents", deleteDocumentsResp.Details.DeletedDocuments)
 	return &deleteDocumentsResp, nil
 }
 
@@ -239,12 +283,25 @@ type GetDocumentResponse map[string]interface{}
 //	}
 //	fmt.Printf("GetDocumentResponse: %+v\n", resp)
 func (c *Client) GetDocument(getDocumentReq *GetDocumentRequest) (*GetDocumentResponse, error) {
-	logger := c.logger.With("method", "GetDocument")
-	err := validate.Struct(getDocumentReq)
+	return c.GetDocumentWithContext(context.Background(), getDocumentReq)
+}
+
+// GetDocumentWithContext is like GetDocument but sends the request with ctx.
+func (c *Client) GetDocumentWithContext(ctx context.Context, getDocumentReq *GetDocumentRequest) (_ *GetDocumentResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetDocument")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetDocument")
+	c.setDefaultIndex(&getDocumentReq.IndexName)
+	err = validate.Struct(getDocumentReq)
 	if err != nil {
 		logger.Error("error validating get document request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, getDocumentReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var getDocumentResp GetDocumentResponse
 	queryParams := map[string]string{}
@@ -252,21 +309,22 @@ func (c *Client) GetDocument(getDocumentReq *GetDocumentRequest) (*GetDocumentRe
 		queryParams["expose_facets"] = strconv.FormatBool(getDocumentReq.ExposeFacets)
 	}
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetDocument", indexName, getDocumentReq).
 		SetQueryParams(queryParams).
 		SetSuccessResult(&getDocumentResp).
-		Get(c.reqClient.BaseURL + "/indexes/" + getDocumentReq.IndexName + "/documents/" + getDocumentReq.DocumentID)
+		Get(c.reqClient.BaseURL + "/indexes/" + indexName + "/documents/" + getDocumentReq.DocumentID)
 	if err != nil {
 		logger.Error("error getting document", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error getting document", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting document: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting document", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting document: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response get document: %+v", getDocumentResp))
+	c.logResponse(ctx, logger, resp, "got document",
+		"index", indexName)
 	return &getDocumentResp, nil
 }
 
@@ -313,12 +371,25 @@ type GetDocumentsResponse struct {
 //	}
 //	fmt.Printf("GetDocumentsResponse: %+v\n", resp)
 func (c *Client) GetDocuments(getDocumentsReq *GetDocumentsRequest) (*GetDocumentsResponse, error) {
-	logger := c.logger.With("method", "GetDocuments")
-	err := validate.Struct(getDocumentsReq)
+	return c.GetDocumentsWithContext(context.Background(), getDocumentsReq)
+}
+
+// GetDocumentsWithContext is like GetDocuments but sends the request with ctx.
+func (c *Client) GetDocumentsWithContext(ctx context.Context, getDocumentsReq *GetDocumentsRequest) (_ *GetDocumentsResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetDocuments")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetDocuments")
+	c.setDefaultIndex(&getDocumentsReq.IndexName)
+	err = validate.Struct(getDocumentsReq)
 	if err != nil {
 		logger.Error("error validating get documents request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, getDocumentsReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var getDocumentsResp GetDocumentsResponse
 	queryParams := map[string]string{}
@@ -326,21 +397,23 @@ func (c *Client) GetDocuments(getDocumentsReq *GetDocumentsRequest) (*GetDocumen
 		queryParams["expose_facets"] = strconv.FormatBool(getDocumentsReq.ExposeFacets)
 	}
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetDocuments", indexName, getDocumentsReq).
 		SetQueryParams(queryParams).
 		SetBody(getDocumentsReq.DocumentIDs).
 		SetSuccessResult(&getDocumentsResp).
-		Get(c.reqClient.BaseURL + "/indexes/" + getDocumentsReq.IndexName + "/documents")
+		Get(c.reqClient.BaseURL + "/indexes/" + indexName + "/documents")
 	if err != nil {
 		logger.Error("error getting documents", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error getting documents", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting documents: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting documents", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting documents: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response get documents: %+v", getDocumentsResp))
+	c.logResponse(ctx, logger, resp, "got documents",
+		"index", indexName,
+		"documents", len(getDocumentsResp.Results))
 	return &getDocumentsResp, nil
 }
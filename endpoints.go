@@ -0,0 +1,410 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// LoadBalancingStrategy selects the endpoint for each request
+type LoadBalancingStrategy int
+
+const (
+	// RoundRobin sends the requests to the healthy endpoints in turn
+	RoundRobin LoadBalancingStrategy = iota
+	// LeastLatency sends the requests to the healthy endpoint with the
+	// lowest recent latency. Endpoints without latency yet are tried first
+	// and old latencies fade, so that a slow endpoint is tried again.
+	LeastLatency
+)
+
+// latencyHalfLife is the age at which a latency sample counts half as much
+const latencyHalfLife = 30 * time.Second
+
+// HealthCheckSettings configures how failing endpoints are ejected and
+// re-admitted
+type HealthCheckSettings struct {
+	// FailureThreshold ejects an endpoint after that many failed requests in a row (default: 3)
+	FailureThreshold int
+	// EjectDuration is how long a passively ejected endpoint is left out
+	// before it is tried again (default: 30s)
+	EjectDuration time.Duration
+	// Interval is the period of the active health probes, 0 disables them
+	Interval time.Duration
+	// Path is the path probed on every endpoint, e.g. "/indexes/my-index/health" (default: "/")
+	Path string
+	// Timeout is the timeout of a single probe (default: 5s)
+	Timeout time.Duration
+}
+
+// EndpointStatus is the state of one endpoint of the client
+type EndpointStatus struct {
+	URL     string
+	Healthy bool
+	// Latency is the moving average of the request latency
+	Latency time.Duration
+}
+
+// WithEndpoints adds more Marqo API endpoints next to the url passed to
+// NewClient. Requests are balanced between the healthy endpoints and
+// idempotent requests such as Search and GetDocuments fail over to another
+// endpoint on network errors and 502, 503 and 504 responses.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://marqo-1:8882",
+//	    marqo.WithEndpoints("http://marqo-2:8882", "http://marqo-3:8882"),
+//	    marqo.WithLoadBalancing(marqo.LeastLatency),
+//	    marqo.WithHealthCheck(marqo.HealthCheckSettings{Interval: 10 * time.Second}),
+//	)
+//	defer client.Close()
+func WithEndpoints(urls ...string) func(*Client) {
+	return func(c *Client) {
+		c.endpointURLs = append(c.endpointURLs, urls...)
+	}
+}
+
+// WithLoadBalancing sets the strategy to select the endpoint of a request (default: RoundRobin)
+func WithLoadBalancing(strategy LoadBalancingStrategy) func(*Client) {
+	return func(c *Client) {
+		c.loadBalancing = strategy
+	}
+}
+
+// WithHealthCheck sets how failing endpoints are ejected and re-admitted.
+// Zero fields of the settings are set to their defaults.
+func WithHealthCheck(settings HealthCheckSettings) func(*Client) {
+	return func(c *Client) {
+		c.healthCheck = &settings
+	}
+}
+
+// Endpoints returns the state of the endpoints of the client
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return []EndpointStatus{{URL: c.url, Healthy: true}}
+	}
+	return c.endpoints.status()
+}
+
+// Close stops the background health probes of the client
+func (c *Client) Close() error {
+	if c.endpoints != nil {
+		c.endpoints.close()
+	}
+	return nil
+}
+
+// endpoint is one Marqo API endpoint
+type endpoint struct {
+	url *url.URL
+	// consecutiveFailures is the number of failed requests in a row
+	consecutiveFailures int
+	// ejectedUntil is zero while the endpoint is healthy
+	ejectedUntil time.Time
+	// latency is the moving average of the request latency
+	latency time.Duration
+	// sampledAt is the time of the last latency sample, zero if none
+	sampledAt time.Time
+}
+
+// decay returns the weight of a latency sample taken at sampledAt
+func decay(sampledAt, now time.Time) float64 {
+	return math.Exp2(-float64(now.Sub(sampledAt)) / float64(latencyHalfLife))
+}
+
+// rank returns the latency of the endpoint faded by the age of its last
+// sample, an endpoint idle for long ranks like a fast one
+func (e *endpoint) rank(now time.Time) time.Duration {
+	return time.Duration(float64(e.latency) * decay(e.sampledAt, now))
+}
+
+// eject leaves the endpoint out until the eject duration passed. Its
+// latency is dropped, so that it is tried first once re-admitted.
+func (e *endpoint) eject(now time.Time, d time.Duration) {
+	e.ejectedUntil = now.Add(d)
+	e.consecutiveFailures = 0
+	e.latency = 0
+	e.sampledAt = time.Time{}
+}
+
+// endpointPool balances the requests between the endpoints
+type endpointPool struct {
+	// base is the url the request urls are built from
+	base     *url.URL
+	strategy LoadBalancingStrategy
+	settings HealthCheckSettings
+	// probe sends the active health probes
+	probe func(ctx context.Context, u *url.URL) error
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newEndpointPool creates the pool for the client url and the extra endpoints
+func newEndpointPool(base string, urls []string, strategy LoadBalancingStrategy, settings HealthCheckSettings) (*endpointPool, error) {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 3
+	}
+	if settings.EjectDuration <= 0 {
+		settings.EjectDuration = 30 * time.Second
+	}
+	if settings.Path == "" {
+		settings.Path = "/"
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = 5 * time.Second
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", base, err)
+	}
+	pool := &endpointPool{
+		base:     baseURL,
+		strategy: strategy,
+		settings: settings,
+	}
+	for _, u := range append([]string{base}, urls...) {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", u, err)
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint %q: scheme and host are required", u)
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{url: parsed})
+	}
+	return pool, nil
+}
+
+// start starts the active health probes if enabled
+func (p *endpointPool) start() {
+	if p.settings.Interval <= 0 || p.probe == nil {
+		return
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	p.mu.Lock()
+	p.stop, p.done = stop, done
+	p.mu.Unlock()
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(p.settings.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.probeAll()
+			}
+		}
+	}()
+}
+
+// close stops the active health probes
+func (p *endpointPool) close() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// probeAll probes every endpoint and ejects or re-admits it
+func (p *endpointPool) probeAll() {
+	for _, e := range p.endpoints {
+		ctx, cancel := context.WithTimeout(context.Background(), p.settings.Timeout)
+		err := p.probe(ctx, e.url.JoinPath(p.settings.Path))
+		cancel()
+
+		p.mu.Lock()
+		if err != nil {
+			e.eject(time.Now(), p.settings.EjectDuration)
+		} else {
+			e.ejectedUntil = time.Time{}
+			e.consecutiveFailures = 0
+		}
+		p.mu.Unlock()
+	}
+}
+
+// candidates returns the endpoints in the order they should be tried
+func (p *endpointPool) candidates() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var healthy, ejected []*endpoint
+	for _, e := range p.endpoints {
+		if e.ejectedUntil.IsZero() || now.After(e.ejectedUntil) {
+			healthy = append(healthy, e)
+		} else {
+			ejected = append(ejected, e)
+		}
+	}
+	// when every endpoint is ejected, try them all rather than failing
+	if len(healthy) == 0 {
+		healthy, ejected = ejected, nil
+	}
+
+	switch p.strategy {
+	case LeastLatency:
+		// endpoints without latency yet are tried first
+		sort.SliceStable(healthy, func(i, j int) bool {
+			if unsampled := healthy[i].sampledAt.IsZero(); unsampled != healthy[j].sampledAt.IsZero() {
+				return unsampled
+			}
+			return healthy[i].rank(now) < healthy[j].rank(now)
+		})
+	default:
+		start := p.next % len(healthy)
+		p.next++
+		rotated := make([]*endpoint, 0, len(healthy))
+		rotated = append(rotated, healthy[start:]...)
+		healthy = append(rotated, healthy[:start]...)
+	}
+	return append(healthy, ejected...)
+}
+
+// record updates the passive health of the endpoint after a request
+func (p *endpointPool) record(e *endpoint, failure bool, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if failure {
+		e.consecutiveFailures++
+		if e.consecutiveFailures >= p.settings.FailureThreshold {
+			e.eject(now, p.settings.EjectDuration)
+		}
+		return
+	}
+	e.consecutiveFailures = 0
+	e.ejectedUntil = time.Time{}
+	if e.sampledAt.IsZero() {
+		e.latency = latency
+	} else {
+		// the average weighs 4/5 on the previous samples, less as they age
+		weight := 0.8 * decay(e.sampledAt, now)
+		e.latency = time.Duration(weight*float64(e.latency) + (1-weight)*float64(latency))
+	}
+	e.sampledAt = now
+}
+
+// status returns the state of the endpoints
+func (p *endpointPool) status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		statuses = append(statuses, EndpointStatus{
+			URL:     e.url.String(),
+			Healthy: e.ejectedUntil.IsZero() || now.After(e.ejectedUntil),
+			Latency: e.latency,
+		})
+	}
+	return statuses
+}
+
+// rewrite points the request url to the endpoint
+func (p *endpointPool) rewrite(r *req.Request, e *endpoint, path string) {
+	u := *r.URL
+	u.Scheme = e.url.Scheme
+	u.Host = e.url.Host
+	u.Path = strings.TrimSuffix(e.url.Path, "/") + path
+	u.RawPath = ""
+	r.URL = &u
+}
+
+// roundTrip is the round trip wrapper which sends the request to the
+// selected endpoint and fails over to the next one if allowed
+func (p *endpointPool) roundTrip(next req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		op := operationFromContext(r.Context())
+		path := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(p.base.Path, "/"))
+
+		var (
+			resp *req.Response
+			err  error
+		)
+		for _, e := range p.candidates() {
+			p.rewrite(r, e, path)
+			start := time.Now()
+			resp, err = next.RoundTrip(r)
+			if errors.Is(err, context.Canceled) || r.Context().Err() != nil {
+				// cancelled or past the deadline of the caller, says
+				// nothing about the endpoint
+				return resp, err
+			}
+			failure := isEndpointFailure(resp, err)
+			p.record(e, failure, time.Since(start))
+			if !failure || !canFailover(op.idempotent, err) {
+				return resp, err
+			}
+		}
+		return resp, err
+	}
+}
+
+// isEndpointFailure reports whether the endpoint failed to serve a request
+// which the caller did not cancel
+func isEndpointFailure(resp *req.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil || resp.Response == nil {
+		return false
+	}
+	switch resp.Response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// canFailover reports whether a failed request can be sent to another
+// endpoint. Non-idempotent requests are only sent again if they never
+// reached the failed endpoint.
+func canFailover(idempotent bool, err error) bool {
+	return idempotent || (err != nil && isConnectError(err))
+}
+
+// probeEndpoint sends an active health probe with a plain GET request
+func (c *Client) probeEndpoint(ctx context.Context, u *url.URL) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("x-api-key", c.apiKey)
+	}
+	resp, err := c.reqClient.GetClient().Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health probe %v: status code: %v", u, resp.StatusCode)
+	}
+	return nil
+}
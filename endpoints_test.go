@@ -0,0 +1,219 @@
+package marqo
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EndpointsTestSuite struct {
+	suite.Suite
+	Logger *slog.Logger
+}
+
+func (suite *EndpointsTestSuite) SetupSuite() {
+	suite.Logger = slog.New(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{
+			AddSource: true,
+			Level:     slog.LevelError,
+		}))
+}
+
+func TestEndpointsTestSuite(t *testing.T) {
+	suite.Run(t, new(EndpointsTestSuite))
+}
+
+// getMockServerForEndpoint returns a server which counts its requests and
+// responds with statusCode
+func getMockServerForEndpoint(statusCode int, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(hits, 1)
+			w.WriteHeader(statusCode)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"results": [], "hits": []}`))
+		}))
+}
+
+func (suite *EndpointsTestSuite) TestFailover() {
+	t := suite.T()
+	var downHits, upHits int32
+	down := getMockServerForEndpoint(http.StatusServiceUnavailable, &downHits)
+	defer down.Close()
+	up := getMockServerForEndpoint(http.StatusOK, &upHits)
+	defer up.Close()
+
+	c, err := NewClient(down.URL,
+		WithLogger(suite.Logger),
+		WithEndpoints(up.URL),
+		WithHealthCheck(HealthCheckSettings{FailureThreshold: 1}),
+	)
+	suite.Require().NoError(err)
+	defer c.Close()
+
+	query := "query"
+	for i := 0; i < 3; i++ {
+		if _, err := c.Search(&SearchRequest{IndexName: "test", Q: &query}); err != nil {
+			t.Fatalf("Client.Search() error = %v, want nil", err)
+		}
+	}
+	if _, err := c.GetDocuments(&GetDocumentsRequest{IndexName: "test", DocumentIDs: []string{"1"}}); err != nil {
+		t.Fatalf("Client.GetDocuments() error = %v, want nil", err)
+	}
+	// the failing endpoint is ejected after its first failure
+	if got := atomic.LoadInt32(&downHits); got != 1 {
+		t.Errorf("requests to failing endpoint = %v, want 1", got)
+	}
+	if got := atomic.LoadInt32(&upHits); got != 4 {
+		t.Errorf("requests to healthy endpoint = %v, want 4", got)
+	}
+	statuses := c.Endpoints()
+	if len(statuses) != 2 || statuses[0].Healthy || !statuses[1].Healthy {
+		t.Errorf("Client.Endpoints() = %+v, want first endpoint ejected", statuses)
+	}
+}
+
+func (suite *EndpointsTestSuite) TestCallerDeadlineKeepsEndpoint() {
+	t := suite.T()
+	var slowHits, otherHits int32
+	slow := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&slowHits, 1)
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}))
+	defer slow.Close()
+	other := getMockServerForEndpoint(http.StatusOK, &otherHits)
+	defer other.Close()
+
+	c, err := NewClient(slow.URL,
+		WithLogger(suite.Logger),
+		WithEndpoints(other.URL),
+		WithLoadBalancing(LeastLatency),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithHealthCheck(HealthCheckSettings{FailureThreshold: 1}),
+	)
+	suite.Require().NoError(err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	query := "query"
+	if _, err := c.SearchWithContext(ctx, &SearchRequest{IndexName: "test", Q: &query}); err == nil {
+		t.Fatalf("Client.SearchWithContext() error = nil, want the deadline")
+	}
+	// neither ejected nor failed over
+	if atomic.LoadInt32(&slowHits) != 1 || atomic.LoadInt32(&otherHits) != 0 {
+		t.Errorf("requests = %v and %v, want 1 and 0", slowHits, otherHits)
+	}
+	for _, status := range c.Endpoints() {
+		if !status.Healthy || status.Latency != 0 {
+			t.Errorf("Client.Endpoints() = %+v, want healthy without latency", status)
+		}
+	}
+}
+
+func (suite *EndpointsTestSuite) TestNoFailoverForNonIdempotentOperations() {
+	t := suite.T()
+	var downHits, upHits int32
+	down := getMockServerForEndpoint(http.StatusServiceUnavailable, &downHits)
+	defer down.Close()
+	up := getMockServerForEndpoint(http.StatusOK, &upHits)
+	defer up.Close()
+
+	c, err := NewClient(down.URL, WithLogger(suite.Logger), WithEndpoints(up.URL))
+	suite.Require().NoError(err)
+
+	if _, err := c.CreateIndex(&CreateIndexRequest{IndexName: "test"}); err == nil {
+		t.Errorf("Client.CreateIndex() error = nil, want error")
+	}
+	if got := atomic.LoadInt32(&upHits); got != 0 {
+		t.Errorf("requests to second endpoint = %v, want 0", got)
+	}
+}
+
+func (suite *EndpointsTestSuite) TestRoundRobin() {
+	t := suite.T()
+	var firstHits, secondHits int32
+	first := getMockServerForEndpoint(http.StatusOK, &firstHits)
+	defer first.Close()
+	second := getMockServerForEndpoint(http.StatusOK, &secondHits)
+	defer second.Close()
+
+	c, err := NewClient(first.URL, WithLogger(suite.Logger), WithEndpoints(second.URL))
+	suite.Require().NoError(err)
+
+	for i := 0; i < 4; i++ {
+		if _, err := c.ListIndexes(); err != nil {
+			t.Fatalf("Client.ListIndexes() error = %v, want nil", err)
+		}
+	}
+	if atomic.LoadInt32(&firstHits) != 2 || atomic.LoadInt32(&secondHits) != 2 {
+		t.Errorf("requests = %v and %v, want 2 and 2", firstHits, secondHits)
+	}
+}
+
+func (suite *EndpointsTestSuite) TestLeastLatency() {
+	t := suite.T()
+	pool, err := newEndpointPool("http://marqo-1:8882",
+		[]string{"http://marqo-2:8882", "http://marqo-3:8882"}, LeastLatency, HealthCheckSettings{})
+	suite.Require().NoError(err)
+	fast, slow, unsampled := pool.endpoints[0], pool.endpoints[1], pool.endpoints[2]
+	pool.record(fast, false, 10*time.Millisecond)
+	pool.record(slow, false, 100*time.Millisecond)
+
+	// the endpoint without latency yet is tried first
+	if got := pool.candidates(); got[0] != unsampled || got[1] != fast || got[2] != slow {
+		t.Errorf("candidates = %v, %v, %v, want marqo-3, marqo-1, marqo-2", got[0].url, got[1].url, got[2].url)
+	}
+	pool.record(unsampled, false, 50*time.Millisecond)
+	if got := pool.candidates(); got[0] != fast {
+		t.Errorf("first candidate = %v, want marqo-1", got[0].url)
+	}
+
+	// the latency of the slow endpoint fades while it is idle
+	slow.sampledAt = slow.sampledAt.Add(-4 * latencyHalfLife)
+	if got := pool.candidates(); got[0] != slow {
+		t.Errorf("first candidate = %v, want marqo-2", got[0].url)
+	}
+	pool.record(slow, false, 20*time.Millisecond)
+	if slow.latency >= 50*time.Millisecond {
+		t.Errorf("latency = %v, want the old sample to weigh less", slow.latency)
+	}
+}
+
+func (suite *EndpointsTestSuite) TestActiveHealthCheck() {
+	t := suite.T()
+	var downHits, upHits int32
+	down := getMockServerForEndpoint(http.StatusInternalServerError, &downHits)
+	defer down.Close()
+	up := getMockServerForEndpoint(http.StatusOK, &upHits)
+	defer up.Close()
+
+	c, err := NewClient(down.URL,
+		WithLogger(suite.Logger),
+		WithEndpoints(up.URL),
+		WithHealthCheck(HealthCheckSettings{Interval: 10 * time.Millisecond}),
+	)
+	suite.Require().NoError(err)
+	defer c.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	statuses := c.Endpoints()
+	if len(statuses) != 2 || statuses[0].Healthy || !statuses[1].Healthy {
+		t.Errorf("Client.Endpoints() = %+v, want first endpoint ejected", statuses)
+	}
+}
+
+func (suite *EndpointsTestSuite) TestInvalidEndpoint() {
+	_, err := NewClient("http://localhost:8882", WithEndpoints("localhost:8883"))
+	suite.Error(err)
+}
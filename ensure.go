@@ -0,0 +1,314 @@
+package marqo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrIndexDrift is matched by the error of EnsureIndex when the settings of
+// an existing index differ from the requested ones
+var ErrIndexDrift = errors.New("marqo: index settings drifted")
+
+// DriftPolicy is what EnsureIndex does when the settings of an existing
+// index differ from the requested ones. Marqo settings cannot be changed
+// after the index is created.
+type DriftPolicy int
+
+const (
+	// DriftPolicyReport logs a warning and returns the drift (default)
+	DriftPolicyReport DriftPolicy = iota
+	// DriftPolicyAccept returns the drift without logging it
+	DriftPolicyAccept
+	// DriftPolicyFail returns an IndexDriftError
+	DriftPolicyFail
+)
+
+// SettingDiff is a setting of an index which differs from the requested one
+type SettingDiff struct {
+	// Field is the path of the setting, e.g. "index_defaults.ann_parameters.space_type"
+	Field string
+	// Want is the requested value
+	Want interface{}
+	// Got is the value of the index, nil if the index does not report it
+	Got interface{}
+}
+
+// String returns the setting with both values
+func (d SettingDiff) String() string {
+	return fmt.Sprintf("%s: want %v, got %v", d.Field, d.Want, d.Got)
+}
+
+// IndexDriftError is the error of EnsureIndex with DriftPolicyFail, it
+// matches ErrIndexDrift
+type IndexDriftError struct {
+	IndexName string
+	Drift     []SettingDiff
+}
+
+// Error returns the drifted settings of the index
+func (e *IndexDriftError) Error() string {
+	diffs := make([]string, len(e.Drift))
+	for i, diff := range e.Drift {
+		diffs[i] = diff.String()
+	}
+	return fmt.Sprintf("marqo: settings of index %s drifted: %s", e.IndexName, strings.Join(diffs, "; "))
+}
+
+// Is reports whether the target is ErrIndexDrift
+func (e *IndexDriftError) Is(target error) bool {
+	return target == ErrIndexDrift
+}
+
+// EnsureIndexResult is the result of EnsureIndex
+type EnsureIndexResult struct {
+	// Created is whether the index was created
+	Created bool
+	// Drift are the settings of the existing index which differ from the
+	// requested ones
+	Drift []SettingDiff
+}
+
+// EnsureIndex makes sure the index exists with the requested settings.
+//
+// This method creates the index if it does not exist, otherwise it compares
+// the settings of the index with createIndexReq. The settings which
+// createIndexReq leaves nil are compared with the defaults of CreateIndex,
+// the other nil settings accept any value.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context of the requests.
+//	createIndexReq (*CreateIndexRequest): The request containing the index details.
+//	policy (DriftPolicy): What to do when the settings differ.
+//
+// Returns:
+//
+//	*EnsureIndexResult: Whether the index was created and the drifted settings.
+//	error: An error if the operation fails, or an IndexDriftError with DriftPolicyFail, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Resolves the index name if it is an alias, and lists the indexes.
+// 2. Creates the index with CreateIndex if it is not listed, and returns.
+// 3. Gets the settings of the existing index.
+// 4. Fills the defaults of CreateIndex in a copy of createIndexReq and compares its settings
+// with the ones of the index, converting the space type to the dialect of the server.
+// 5. Logs, returns or fails on the drift according to policy.
+//
+// Example usage:
+//
+//	result, err := client.EnsureIndex(ctx, &marqo.CreateIndexRequest{
+//	    IndexName: "example_index",
+//	    IndexDefaults: &marqo.IndexDefaults{Model: &model},
+//	}, marqo.DriftPolicyFail)
+//	if errors.Is(err, marqo.ErrIndexDrift) {
+//	    log.Fatalf("Index must be rebuilt: %v", err)
+//	}
+//	fmt.Printf("created: %v\n", result.Created)
+func (c *Client) EnsureIndex(ctx context.Context, createIndexReq *CreateIndexRequest, policy DriftPolicy) (_ *EnsureIndexResult, err error) {
+	ctx, span := c.startSpan(ctx, "EnsureIndex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("EnsureIndex")
+	err = validate.Struct(createIndexReq)
+	if err != nil {
+		logger.Error("error validating ensure index request", "error", err)
+		return nil, err
+	}
+	indexName, err := c.resolveIndex(ctx, createIndexReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
+	req := *createIndexReq
+	req.IndexName = indexName
+	createIndexReq = &req
+
+	listIndexesResp, err := c.ListIndexesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := false
+	for _, result := range listIndexesResp.Results {
+		if result.IndexName == createIndexReq.IndexName {
+			exists = true
+			break
+		}
+	}
+	// the index was created with the defaults of CreateIndex, which fills
+	// them in the request, so compare a copy with the defaults filled in
+	want := *createIndexReq
+	if createIndexReq.IndexDefaults != nil {
+		data, err := json.Marshal(createIndexReq.IndexDefaults)
+		if err != nil {
+			return nil, err
+		}
+		want.IndexDefaults = new(IndexDefaults)
+		if err := json.Unmarshal(data, want.IndexDefaults); err != nil {
+			return nil, err
+		}
+	}
+	setDefaultCreateIndexRequest(&want)
+	if !exists {
+		_, err = c.CreateIndexWithContext(ctx, createIndexReq)
+		switch {
+		case err == nil:
+			return &EnsureIndexResult{Created: true}, nil
+		case !errors.Is(err, ErrIndexAlreadyExists):
+			return nil, err
+		}
+		// created concurrently, compare its settings
+	}
+
+	// resolved already, an alias may shadow the index
+	settings, err := c.GetIndexSettingsWithContext(withPhysicalIndex(ctx),
+		&GetIndexSettingsRequest{IndexName: createIndexReq.IndexName})
+	if err != nil {
+		return nil, err
+	}
+	dialect, err := c.DetectDialect(ctx)
+	if err != nil {
+		logger.Error("error detecting dialect", "error", err)
+		return nil, err
+	}
+	drift := diffIndexSettings(&want, settings, dialect)
+	result := &EnsureIndexResult{Drift: drift}
+	if len(drift) == 0 {
+		logger.Info("index settings match", "index", createIndexReq.IndexName)
+		return result, nil
+	}
+
+	switch policy {
+	case DriftPolicyFail:
+		err = &IndexDriftError{IndexName: createIndexReq.IndexName, Drift: drift}
+		logger.Error("error ensuring index", "index", createIndexReq.IndexName, "error", err)
+		return result, err
+	case DriftPolicyReport:
+		for _, diff := range drift {
+			logger.Warn("index setting drifted", "index", createIndexReq.IndexName,
+				"field", diff.Field, "want", diff.Want, "got", diff.Got)
+		}
+	}
+	return result, nil
+}
+
+// diffIndexSettings returns the settings of want which differ in got. The
+// space type of want is converted to the Marqo 2.x one for DialectV2.
+func diffIndexSettings(want *CreateIndexRequest, got *GetIndexSettingsResponse, dialect Dialect) []SettingDiff {
+	var drift []SettingDiff
+	add := func(field string, wantValue, gotValue interface{}) {
+		drift = append(drift, SettingDiff{Field: field, Want: wantValue, Got: gotValue})
+	}
+
+	if want.IndexDefaults != nil {
+		defaults := *want.IndexDefaults
+		if dialect == DialectV2 && defaults.ANNParameters != nil {
+			annParameters := *defaults.ANNParameters
+			annParameters.SpaceType = spaceTypeV2(annParameters.SpaceType, defaults.NormalizeEmbeddings)
+			defaults.ANNParameters = &annParameters
+		}
+		if want.Type != nil && *want.Type == IndexTypeStructured {
+			// not a setting of structured indexes
+			defaults.TreatURLsAndPointersAsImages = nil
+		}
+		gotDefaults := got.IndexDefaults
+		if gotDefaults == nil {
+			gotDefaults = &IndexDefaults{}
+		}
+		diffStruct("index_defaults", reflect.ValueOf(defaults), reflect.ValueOf(*gotDefaults), add)
+	}
+	if want.NumberOfShards != nil && dialect != DialectV2 {
+		diffValue("number_of_shards", reflect.ValueOf(want.NumberOfShards), reflect.ValueOf(got.NumberOfShards), add)
+	}
+	if want.NumberOfReplicas != nil && dialect != DialectV2 {
+		diffValue("number_of_replicas", reflect.ValueOf(want.NumberOfReplicas), reflect.ValueOf(got.NumberOfReplicas), add)
+	}
+	if want.Type != nil {
+		gotType := got.Type
+		if gotType == "" && dialect == DialectV2 {
+			gotType = IndexTypeUnstructured
+		}
+		if *want.Type != gotType {
+			add("type", *want.Type, gotType)
+		}
+	}
+	if want.VectorNumericType != nil && dialect == DialectV2 {
+		diffValue("vector_numeric_type", reflect.ValueOf(want.VectorNumericType), reflect.ValueOf(got.VectorNumericType), add)
+	}
+	if want.TensorFields != nil {
+		wantFields := append([]string(nil), want.TensorFields...)
+		gotFields := append([]string(nil), got.TensorFields...)
+		sort.Strings(wantFields)
+		sort.Strings(gotFields)
+		if !reflect.DeepEqual(wantFields, gotFields) && (len(wantFields) > 0 || len(gotFields) > 0) {
+			add("tensor_fields", wantFields, gotFields)
+		}
+	}
+	if want.AllFields != nil {
+		gotFields := make(map[string]FieldDefinition, len(got.AllFields))
+		for _, field := range got.AllFields {
+			gotFields[field.Name] = field
+		}
+		for _, field := range want.AllFields {
+			gotField, ok := gotFields[field.Name]
+			switch {
+			case !ok:
+				add("all_fields."+field.Name, field, nil)
+			case !equalFieldDefinitions(field, gotField):
+				add("all_fields."+field.Name, field, gotField)
+			}
+			delete(gotFields, field.Name)
+		}
+		for _, field := range got.AllFields {
+			if _, ok := gotFields[field.Name]; ok {
+				add("all_fields."+field.Name, nil, field)
+			}
+		}
+	}
+	return drift
+}
+
+// equalFieldDefinitions reports whether the fields are equal, regardless of
+// the order of the features
+func equalFieldDefinitions(a, b FieldDefinition) bool {
+	featuresA := append([]string(nil), a.Features...)
+	featuresB := append([]string(nil), b.Features...)
+	sort.Strings(featuresA)
+	sort.Strings(featuresB)
+	return a.Name == b.Name && a.Type == b.Type &&
+		(len(featuresA) == 0 && len(featuresB) == 0 || reflect.DeepEqual(featuresA, featuresB)) &&
+		(len(a.DependentFields) == 0 && len(b.DependentFields) == 0 || reflect.DeepEqual(a.DependentFields, b.DependentFields))
+}
+
+// diffStruct compares the fields of the structs want and got, named by
+// their json name after prefix
+func diffStruct(prefix string, want, got reflect.Value, add func(field string, want, got interface{})) {
+	for i := 0; i < want.NumField(); i++ {
+		name, _, _ := strings.Cut(want.Type().Field(i).Tag.Get("json"), ",")
+		diffValue(prefix+"."+name, want.Field(i), got.Field(i), add)
+	}
+}
+
+// diffValue compares the pointers want and got, nil wants are not compared
+func diffValue(field string, want, got reflect.Value, add func(field string, want, got interface{})) {
+	if want.IsNil() {
+		return
+	}
+	if want.Elem().Kind() == reflect.Struct {
+		if got.IsNil() {
+			got = reflect.New(want.Elem().Type())
+		}
+		diffStruct(field, want.Elem(), got.Elem(), add)
+		return
+	}
+	if got.IsNil() {
+		add(field, want.Elem().Interface(), nil)
+		return
+	}
+	if !reflect.DeepEqual(want.Elem().Interface(), got.Elem().Interface()) {
+		add(field, want.Elem().Interface(), got.Elem().Interface())
+	}
+}
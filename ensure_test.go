@@ -0,0 +1,199 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// getMockServerForEnsureIndex returns a server reporting version, listing
+// the index test if settings is set and returning settings as its settings
+func getMockServerForEnsureIndex(version, settings string, creates *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var resp string
+			switch {
+			case r.URL.Path == "/":
+				resp = `{"message": "Welcome to Marqo", "version": "` + version + `"}`
+			case r.URL.Path == "/indexes" && settings == "":
+				resp = `{"results": [{"index_name": "other"}]}`
+			case r.URL.Path == "/indexes":
+				resp = `{"results": [{"index_name": "other"}, {"index_name": "test"}]}`
+			case r.URL.Path == "/indexes/test/settings":
+				resp = settings
+			case r.URL.Path == "/indexes/test" && r.Method == http.MethodPost:
+				atomic.AddInt32(creates, 1)
+				resp = `{"acknowledged": true, "index": "test"}`
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(resp))
+		}))
+}
+
+func TestClient_EnsureIndex(t *testing.T) {
+	const (
+		settingsV1 = `{"index_defaults": {"treat_urls_and_pointers_as_images": false, "model": "hf/all_datasets_v4_MiniLM-L6",
+			"normalize_embeddings": true, "text_preprocessing": {"split_length": 2, "split_overlap": 0, "split_method": "sentence"},
+			"ann_parameters": {"space_type": "cosinesimil", "parameters": {"ef_construction": 128, "m": 16}}},
+			"number_of_shards": 3, "number_of_replicas": 0}`
+		settingsV1Custom = `{"index_defaults": {"treat_urls_and_pointers_as_images": false, "model": "hf/e5-base-v2",
+			"normalize_embeddings": true, "text_preprocessing": {"split_length": 4, "split_overlap": 0, "split_method": "sentence"}},
+			"number_of_shards": 5, "number_of_replicas": 0}`
+		settingsV2 = `{"type": "structured", "model": "hf/e5-base-v2", "normalizeEmbeddings": true,
+			"allFields": [{"name": "title", "type": "text", "features": ["lexical_search", "filter"]}, {"name": "price", "type": "float"}],
+			"tensorFields": ["title"], "annParameters": {"spaceType": "prenormalized-angular", "parameters": {"efConstruction": 512, "m": 16}}}`
+	)
+	model := func(name string) *string { return &name }
+	intPtr := func(i int) *int { return &i }
+	structured := IndexTypeStructured
+	cosine := "cosinesimil"
+
+	tests := []struct {
+		name        string
+		version     string
+		settings    string
+		req         *CreateIndexRequest
+		policy      DriftPolicy
+		wantCreated bool
+		wantDrift   []string
+		wantErr     error
+	}{
+		{
+			name:        "missing index is created",
+			version:     "1.5.1",
+			req:         &CreateIndexRequest{IndexName: "test", IndexDefaults: &IndexDefaults{Model: model(string(ModelE5Base))}},
+			wantCreated: true,
+		},
+		{
+			name:     "matching settings",
+			version:  "1.5.1",
+			settings: settingsV1,
+			req: &CreateIndexRequest{
+				IndexName: "test",
+				IndexDefaults: &IndexDefaults{
+					Model:             model(string(ModelMiniLML6)),
+					TextPreprocessing: &TextPreprocessing{SplitLength: intPtr(2)},
+				},
+				NumberOfShards: intPtr(3),
+			},
+			policy: DriftPolicyFail,
+		},
+		{
+			name:     "drift fails",
+			version:  "1.5.1",
+			settings: settingsV1,
+			req: &CreateIndexRequest{
+				IndexName: "test",
+				IndexDefaults: &IndexDefaults{
+					Model:             model(string(ModelE5Base)),
+					TextPreprocessing: &TextPreprocessing{SplitMethod: model("word")},
+					ANNParameters:     &ANNParameters{Parameters: &HSNWMethodParameters{EFConstruction: intPtr(512)}},
+					ImagePreprocessing: &ImagePreprocessing{
+						PatchMethod: model("simple"),
+					},
+				},
+				NumberOfShards: intPtr(5),
+			},
+			policy: DriftPolicyFail,
+			wantDrift: []string{
+				"index_defaults.ann_parameters.parameters.ef_construction: want 512, got 128",
+				"index_defaults.image_preprocessing.patch_method: want simple, got <nil>",
+				"index_defaults.model: want hf/e5-base-v2, got hf/all_datasets_v4_MiniLM-L6",
+				"index_defaults.text_preprocessing.split_method: want word, got sentence",
+				"number_of_shards: want 5, got 3",
+			},
+			wantErr: ErrIndexDrift,
+		},
+		{
+			name:      "drift is reported",
+			version:   "1.5.1",
+			settings:  settingsV1,
+			req:       &CreateIndexRequest{IndexName: "test", NumberOfReplicas: intPtr(1)},
+			policy:    DriftPolicyReport,
+			wantDrift: []string{"number_of_replicas: want 1, got 0"},
+		},
+		{
+			name:     "defaults are compared",
+			version:  "1.5.1",
+			settings: settingsV1Custom,
+			req: &CreateIndexRequest{
+				IndexName:     "test",
+				IndexDefaults: &IndexDefaults{TextPreprocessing: &TextPreprocessing{}},
+			},
+			policy: DriftPolicyAccept,
+			wantDrift: []string{
+				"index_defaults.model: want hf/all_datasets_v4_MiniLM-L6, got hf/e5-base-v2",
+				"index_defaults.text_preprocessing.split_length: want 2, got 4",
+				"number_of_shards: want 3, got 5",
+			},
+		},
+		{
+			name:     "marqo 2.x structured index",
+			version:  "2.5.0",
+			settings: settingsV2,
+			req: &CreateIndexRequest{
+				IndexName: "test",
+				Type:      &structured,
+				IndexDefaults: &IndexDefaults{
+					Model:         model(string(ModelE5Base)),
+					ANNParameters: &ANNParameters{SpaceType: &cosine},
+				},
+				AllFields: []FieldDefinition{
+					{Name: "title", Type: FieldTypeText, Features: []string{FieldFeatureFilter, FieldFeatureLexicalSearch}},
+					{Name: "image", Type: FieldTypeImagePointer},
+				},
+				TensorFields:   []string{"title", "image"},
+				NumberOfShards: intPtr(5),
+			},
+			policy: DriftPolicyAccept,
+			wantDrift: []string{
+				"all_fields.image: want {image image_pointer [] map[]}, got <nil>",
+				"all_fields.price: want <nil>, got {price float [] map[]}",
+				"index_defaults.ann_parameters.parameters.ef_construction: want 128, got 512",
+				"tensor_fields: want [image title], got [title]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var creates int32
+			mockServer := getMockServerForEnsureIndex(tt.version, tt.settings, &creates)
+			defer mockServer.Close()
+
+			c, err := NewClient(mockServer.URL, WithDialect(DialectAuto))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			result, err := c.EnsureIndex(context.Background(), tt.req, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Client.EnsureIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Created != tt.wantCreated || (atomic.LoadInt32(&creates) == 1) != tt.wantCreated {
+				t.Errorf("Client.EnsureIndex() created = %v, creates = %d, want %v", result.Created, creates, tt.wantCreated)
+			}
+
+			drift := make([]string, len(result.Drift))
+			for i, diff := range result.Drift {
+				drift[i] = diff.String()
+			}
+			sort.Strings(drift)
+			if strings.Join(drift, "\n") != strings.Join(tt.wantDrift, "\n") {
+				t.Errorf("Client.EnsureIndex() drift = %q, want %q", drift, tt.wantDrift)
+			}
+			var driftErr *IndexDriftError
+			if errors.As(err, &driftErr) && len(driftErr.Drift) != len(tt.wantDrift) {
+				t.Errorf("IndexDriftError = %v", driftErr)
+			}
+		})
+	}
+}
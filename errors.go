@@ -0,0 +1,129 @@
+package marqo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/imroc/req/v3"
+)
+
+// Sentinel errors which can be matched with errors.Is against errors returned
+// by the client, e.g.
+//
+//	_, err := client.GetIndexStats(&GetIndexStatsRequest{IndexName: "missing"})
+//	if errors.Is(err, marqo.ErrIndexNotFound) {
+//	    // create the index
+//	}
+var (
+	// ErrIndexNotFound is returned when the requested index does not exist
+	ErrIndexNotFound = errors.New("marqo: index not found")
+	// ErrIndexAlreadyExists is returned when creating an index which already exists
+	ErrIndexAlreadyExists = errors.New("marqo: index already exists")
+	// ErrDocumentNotFound is returned when the requested document does not exist
+	ErrDocumentNotFound = errors.New("marqo: document not found")
+	// ErrInvalidArgument is returned when the server rejects the request,
+	// e.g. because of a bad filter string or an invalid field name, or when
+	// the client does, e.g. because of an invalid structured index schema
+	ErrInvalidArgument = errors.New("marqo: invalid argument")
+	// ErrUnauthorized is returned when the server rejects the credentials
+	ErrUnauthorized = errors.New("marqo: unauthorized")
+)
+
+// Marqo error codes which map to the sentinel errors
+const (
+	errorCodeIndexNotFound      = "index_not_found"
+	errorCodeIndexAlreadyExists = "index_already_exists"
+	errorCodeDocumentNotFound   = "document_not_found"
+	errorCodeUnauthorized       = "unauthorized"
+)
+
+// invalidArgumentCodes are the Marqo error codes reported for malformed requests
+var invalidArgumentCodes = map[string]bool{
+	"invalid_argument":    true,
+	"invalid_field_name":  true,
+	"invalid_document_id": true,
+	"invalid_index_name":  true,
+	"bad_request":         true,
+	"illegal_request":     true,
+}
+
+// APIError is the error returned when the Marqo server responds with a
+// non-success status code. It holds the decoded Marqo error body, if any.
+//
+// Use errors.As to inspect it:
+//
+//	var apiErr *marqo.APIError
+//	if errors.As(err, &apiErr) {
+//	    fmt.Println(apiErr.StatusCode, apiErr.Code, apiErr.Message)
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int `json:"-"`
+	// Code is the Marqo error code, e.g. "index_not_found"
+	Code string `json:"code"`
+	// Type is the Marqo error type, e.g. "invalid_request"
+	Type string `json:"type"`
+	// Message is the human readable error message
+	Message string `json:"message"`
+	// Link points to the Marqo documentation for the error
+	Link string `json:"link"`
+	// Body is the raw response body
+	Body []byte `json:"-"`
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("status code: %v", e.StatusCode)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	switch {
+	case e.Message != "":
+		msg += ": " + e.Message
+	case len(e.Body) > 0 && e.Code == "":
+		msg += ": " + string(e.Body)
+	}
+	return msg
+}
+
+// Is reports whether the error matches one of the sentinel errors. The
+// Marqo error code is used when present, otherwise the HTTP status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrIndexNotFound:
+		return e.Code == errorCodeIndexNotFound
+	case ErrIndexAlreadyExists:
+		return e.Code == errorCodeIndexAlreadyExists ||
+			(e.Code == "" && e.StatusCode == http.StatusConflict)
+	case ErrDocumentNotFound:
+		return e.Code == errorCodeDocumentNotFound
+	case ErrInvalidArgument:
+		return invalidArgumentCodes[e.Code] ||
+			(e.Code == "" && (e.StatusCode == http.StatusBadRequest ||
+				e.StatusCode == http.StatusUnprocessableEntity))
+	case ErrUnauthorized:
+		return e.Code == errorCodeUnauthorized ||
+			e.StatusCode == http.StatusUnauthorized ||
+			e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// newAPIError creates an APIError from the response, decoding the Marqo
+// error body if the server sent one
+func newAPIError(resp *req.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.Response.StatusCode,
+	}
+	body, err := resp.ToBytes()
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+	apiErr.Body = body
+	// the body is not always a Marqo error, e.g. when it comes from a proxy
+	// nolint
+	json.Unmarshal(body, apiErr)
+	return apiErr
+}
@@ -0,0 +1,120 @@
+package marqo
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func getMockServerForError(statusCode int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(statusCode)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(body))
+		}))
+}
+
+func TestAPIError(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{
+			AddSource: true,
+			Level:     slog.LevelError,
+		}))
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantIs     error
+		wantNotIs  error
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:       "index not found",
+			statusCode: http.StatusNotFound,
+			body: `{
+				"message": "Index test does not exist",
+				"code": "index_not_found",
+				"type": "invalid_request",
+				"link": "https://docs.marqo.ai"
+			}`,
+			wantIs:    ErrIndexNotFound,
+			wantNotIs: ErrDocumentNotFound,
+			wantCode:  "index_not_found",
+			wantMsg:   "Index test does not exist",
+		},
+		{
+			name:       "bad filter syntax",
+			statusCode: http.StatusBadRequest,
+			body: `{
+				"message": "Error parsing filter string",
+				"code": "invalid_argument",
+				"type": "invalid_request"
+			}`,
+			wantIs:    ErrInvalidArgument,
+			wantNotIs: ErrIndexNotFound,
+			wantCode:  "invalid_argument",
+			wantMsg:   "Error parsing filter string",
+		},
+		{
+			name:       "unauthorized without marqo body",
+			statusCode: http.StatusUnauthorized,
+			body:       `Unauthorized`,
+			wantIs:     ErrUnauthorized,
+			wantNotIs:  ErrInvalidArgument,
+		},
+		{
+			name:       "index already exists",
+			statusCode: http.StatusConflict,
+			body: `{
+				"message": "Index test already exists",
+				"code": "index_already_exists",
+				"type": "invalid_request"
+			}`,
+			wantIs:   ErrIndexAlreadyExists,
+			wantCode: "index_already_exists",
+			wantMsg:  "Index test already exists",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := getMockServerForError(tt.statusCode, tt.body)
+			defer mockServer.Close()
+			c, err := NewClient(mockServer.URL, WithLogger(logger))
+			if err != nil {
+				t.Errorf("Client.Connect() error = %v", err)
+				return
+			}
+			_, err = c.GetIndexStats(&GetIndexStatsRequest{IndexName: "test"})
+			if !errors.Is(err, tt.wantIs) {
+				t.Errorf("Client.GetIndexStats() error = %v, want %v", err, tt.wantIs)
+			}
+			if tt.wantNotIs != nil && errors.Is(err, tt.wantNotIs) {
+				t.Errorf("Client.GetIndexStats() error = %v, should not be %v", err, tt.wantNotIs)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Errorf("Client.GetIndexStats() error = %v, want *APIError", err)
+				return
+			}
+			if apiErr.StatusCode != tt.statusCode {
+				t.Errorf("APIError.StatusCode = %v, want %v", apiErr.StatusCode, tt.statusCode)
+			}
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("APIError.Code = %v, want %v", apiErr.Code, tt.wantCode)
+			}
+			if apiErr.Message != tt.wantMsg {
+				t.Errorf("APIError.Message = %v, want %v", apiErr.Message, tt.wantMsg)
+			}
+			if len(apiErr.Body) == 0 {
+				t.Errorf("APIError.Body is empty")
+			}
+		})
+	}
+}
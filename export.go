@@ -0,0 +1,515 @@
+package marqo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// tensorFacetsField is the document field holding the chunks and embeddings
+// of its tensor fields, returned by GetDocuments with ExposeFacets
+const tensorFacetsField = "_tensor_facets"
+
+// ExportIndexRequest is the request for exporting the documents of an index
+type ExportIndexRequest struct {
+	IndexName string `validate:"required"`
+	// DocumentIDs are the documents to export, every document of the index
+	// if empty
+	DocumentIDs []string
+	// BatchSize is the number of documents fetched per request (default: 100)
+	BatchSize int `validate:"gte=0"`
+	// ExposeFacets exports the _tensor_facets of the documents, which hold
+	// their embeddings, so that ImportIndex with CustomVectors does not
+	// compute them again
+	ExposeFacets bool
+	// Gzip compresses the export
+	Gzip bool
+}
+
+// ExportIndexResponse is the response for exporting the documents of an index
+type ExportIndexResponse struct {
+	DocumentsExported int
+	// DocumentsMissing is the number of documents of DocumentIDs, or
+	// deleted since they were listed, which were not found in the index
+	DocumentsMissing int
+}
+
+// ExportIndex writes the documents of an index to w as JSON lines.
+//
+// This method writes one document per line, with its _id and, with
+// ExposeFacets, its _tensor_facets. The export can be replayed with
+// ImportIndex. Without DocumentIDs, every document of the index is listed by
+// paging through a lexical search retrieving only the _id of the documents,
+// which fails if documents are still missing after searching the index again,
+// e.g. because the index has more documents than the server pages through.
+// Documents which are not found once listed are skipped and counted in
+// DocumentsMissing.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context of the requests.
+//	exportIndexReq (*ExportIndexRequest): The request containing the index and the export format.
+//	w (io.Writer): The writer the documents are written to.
+//
+// Returns:
+//
+//	*ExportIndexResponse: The number of exported documents.
+//	error: An error if the operation fails, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Validates the request and resolves the index alias, if any.
+// 2. Lists the ids of every document of the index if DocumentIDs is empty, comparing
+// their number with GetIndexStats.
+// 3. Fetches the documents in batches with GetDocuments.
+// 4. Writes every document without the search metadata, gzip compressed if Gzip is set.
+//
+// Example usage:
+//
+//	f, err := os.Create("example_index.jsonl.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	exportResp, err := client.ExportIndex(ctx, &marqo.ExportIndexRequest{
+//	    IndexName:    "example_index",
+//	    ExposeFacets: true,
+//	    Gzip:         true,
+//	}, f)
+//	if err != nil {
+//	    log.Fatalf("Failed to export index: %v", err)
+//	}
+//	fmt.Printf("Exported %d documents\n", exportResp.DocumentsExported)
+func (c *Client) ExportIndex(ctx context.Context, exportIndexReq *ExportIndexRequest, w io.Writer) (_ *ExportIndexResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ExportIndex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("ExportIndex")
+	c.setDefaultIndex(&exportIndexReq.IndexName)
+	err = validate.Struct(exportIndexReq)
+	if err != nil {
+		logger.Error("error validating export index request", "error", err)
+		return nil, err
+	}
+	indexName, err := c.resolveIndex(ctx, exportIndexReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
+
+	var gzipWriter *gzip.Writer
+	if exportIndexReq.Gzip {
+		gzipWriter = gzip.NewWriter(w)
+		w = gzipWriter
+	}
+	bufWriter := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bufWriter)
+	exportIndexResp := &ExportIndexResponse{}
+	exportIndexResp.DocumentsMissing, err = c.scanDocuments(ctx, indexName, exportIndexReq.DocumentIDs,
+		exportIndexReq.BatchSize, exportIndexReq.ExposeFacets,
+		func(documents []map[string]interface{}) error {
+			for _, document := range documents {
+				exported := stripDocumentMetadata(document)
+				if facets, ok := document[tensorFacetsField]; ok {
+					exported[tensorFacetsField] = facets
+				}
+				if err := encoder.Encode(exported); err != nil {
+					return err
+				}
+				exportIndexResp.DocumentsExported++
+			}
+			return nil
+		})
+	// flushed even if the export failed, so that w holds the exported documents
+	if flushErr := bufWriter.Flush(); err == nil {
+		err = flushErr
+	}
+	if gzipWriter != nil {
+		if closeErr := gzipWriter.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		logger.Error("error exporting index", "index", indexName, "exported", exportIndexResp.DocumentsExported, "error", err)
+		return exportIndexResp, err
+	}
+
+	if exportIndexResp.DocumentsMissing > 0 {
+		logger.Warn("documents not found", "index", indexName, "missing", exportIndexResp.DocumentsMissing)
+	}
+	logger.Info("index exported", "index", indexName, "documents", exportIndexResp.DocumentsExported)
+	return exportIndexResp, nil
+}
+
+// ImportIndexRequest is the request for importing documents exported with
+// ExportIndex
+type ImportIndexRequest struct {
+	IndexName string `validate:"required"`
+	// TensorFields are the tensor fields of the documents, required by
+	// unstructured Marqo 2.x indexes
+	TensorFields []string
+	// BatchSize is the number of documents upserted per request (default: 100)
+	BatchSize int `validate:"gte=0"`
+	// UseExistingTensors reuses the tensors of the documents already in the
+	// index whose tensor fields did not change. It only skips the
+	// vectorisation when importing into the index the documents were exported
+	// from, e.g. to restore it, a new index has no tensors to reuse.
+	UseExistingTensors bool
+	// CustomVectors imports the exported embeddings of the tensor fields
+	// which were not chunked as custom vectors, so that they are not computed
+	// again, even in a new index. It requires an unstructured Marqo 2.x
+	// index, on Marqo 1.x the documents imported into a new index are always
+	// vectorised again.
+	CustomVectors bool
+	// CheckpointPath is the file recording the progress of the import. If it
+	// exists, the import resumes after the documents it records, once the
+	// digest of the skipped lines matches the recorded one, and it is removed
+	// once the import completes.
+	CheckpointPath string
+}
+
+// ImportIndexResponse is the response for importing documents
+type ImportIndexResponse struct {
+	// DocumentsImported is the number of documents upserted by this call
+	DocumentsImported int
+	// DocumentsSkipped is the number of documents imported before the
+	// checkpoint, which were skipped
+	DocumentsSkipped int
+}
+
+// importCheckpoint is the content of the checkpoint file of ImportIndex
+type importCheckpoint struct {
+	IndexName string `json:"index_name"`
+	Lines     int    `json:"lines"`
+	// Digest is the SHA-256 of the imported lines, which tells whether the
+	// export resumed is the one the checkpoint was recorded for
+	Digest string `json:"digest"`
+}
+
+// ImportIndex upserts the documents exported with ExportIndex from r.
+//
+// This method reads one document per line, gzip compressed or not, and
+// upserts them in batches. The _tensor_facets of the documents are dropped,
+// unless CustomVectors is set. The documents imported into a new index are
+// vectorised again, unless CustomVectors is set on Marqo 2.x.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context of the requests.
+//	importIndexReq (*ImportIndexRequest): The request containing the index and the import settings.
+//	r (io.Reader): The reader of the export.
+//
+// Returns:
+//
+//	*ImportIndexResponse: The number of imported and skipped documents.
+//	error: An error if the operation fails, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Validates the request and reads the checkpoint file, if any.
+// 2. Detects gzip compression and skips the lines recorded by the checkpoint, failing
+// with ErrInvalidArgument if their digest differs from the recorded one.
+// 3. Upserts the documents in batches, with their unchunked embeddings as custom vectors
+// if CustomVectors is set.
+// 4. Records the lines imported in the checkpoint file after every batch.
+// 5. Removes the checkpoint file once every document is imported.
+//
+// Example usage:
+//
+//	f, err := os.Open("example_index.jsonl.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	importResp, err := client.ImportIndex(ctx, &marqo.ImportIndexRequest{
+//	    IndexName:      "example_index",
+//	    TensorFields:   []string{"title"},
+//	    CustomVectors:  true,
+//	    CheckpointPath: "example_index.checkpoint",
+//	}, f)
+//	if err != nil {
+//	    log.Fatalf("Failed to import index, run again to resume: %v", err)
+//	}
+//	fmt.Printf("Imported %d documents\n", importResp.DocumentsImported)
+func (c *Client) ImportIndex(ctx context.Context, importIndexReq *ImportIndexRequest, r io.Reader) (_ *ImportIndexResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ImportIndex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("ImportIndex")
+	c.setDefaultIndex(&importIndexReq.IndexName)
+	err = validate.Struct(importIndexReq)
+	if err != nil {
+		logger.Error("error validating import index request", "error", err)
+		return nil, err
+	}
+	if importIndexReq.CustomVectors {
+		dialect, err := c.DetectDialect(ctx)
+		if err != nil {
+			logger.Error("error detecting dialect", "error", err)
+			return nil, err
+		}
+		if dialect != DialectV2 {
+			return nil, fmt.Errorf("error importing custom vectors: %w", ErrUnsupportedOperation)
+		}
+	}
+	checkpoint, err := readImportCheckpoint(importIndexReq.CheckpointPath)
+	if err != nil {
+		logger.Error("error reading checkpoint", "error", err)
+		return nil, err
+	}
+	if checkpoint.IndexName != "" && checkpoint.IndexName != importIndexReq.IndexName {
+		return nil, fmt.Errorf("%w: checkpoint %s is for index %s", ErrInvalidArgument,
+			importIndexReq.CheckpointPath, checkpoint.IndexName)
+	}
+	checkpoint.IndexName = importIndexReq.IndexName
+
+	reader, err := newImportReader(r)
+	if err != nil {
+		logger.Error("error reading export", "error", err)
+		return nil, err
+	}
+	batchSize := importIndexReq.BatchSize
+	if batchSize == 0 {
+		batchSize = 100
+	}
+
+	importIndexResp := &ImportIndexResponse{}
+	digest := sha256.New()
+	var batch []map[string]interface{}
+	flush := func(lines int) error {
+		if len(batch) > 0 {
+			if err := c.upsertBatch(ctx, newImportUpsertRequest(importIndexReq, batch)); err != nil {
+				return err
+			}
+			importIndexResp.DocumentsImported += len(batch)
+			batch = batch[:0]
+		}
+		checkpoint.Lines = lines
+		checkpoint.Digest = hex.EncodeToString(digest.Sum(nil))
+		return writeImportCheckpoint(importIndexReq.CheckpointPath, checkpoint)
+	}
+
+	lines := 0
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			lines++
+			digest.Write(trimmed)
+			digest.Write([]byte{'\n'})
+			if lines <= checkpoint.Lines {
+				importIndexResp.DocumentsSkipped++
+				if lines == checkpoint.Lines && hex.EncodeToString(digest.Sum(nil)) != checkpoint.Digest {
+					return importIndexResp, fmt.Errorf("%w: checkpoint %s was recorded for another export",
+						ErrInvalidArgument, importIndexReq.CheckpointPath)
+				}
+			} else {
+				var document map[string]interface{}
+				if err := json.Unmarshal(line, &document); err != nil {
+					err = fmt.Errorf("%w: line %d: %v", ErrInvalidArgument, lines, err)
+					logger.Error("error decoding document", "error", err)
+					return importIndexResp, err
+				}
+				batch = append(batch, document)
+			}
+		}
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			logger.Error("error reading export", "error", readErr)
+			return importIndexResp, readErr
+		}
+		if len(batch) == batchSize || (errors.Is(readErr, io.EOF) && len(batch) > 0) {
+			if err := flush(lines); err != nil {
+				logger.Error("error importing documents", "index", importIndexReq.IndexName,
+					"imported", importIndexResp.DocumentsImported, "error", err)
+				return importIndexResp, err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+	}
+	if lines < checkpoint.Lines {
+		return importIndexResp, fmt.Errorf("%w: checkpoint %s records %d lines, the export has %d",
+			ErrInvalidArgument, importIndexReq.CheckpointPath, checkpoint.Lines, lines)
+	}
+
+	if importIndexReq.CheckpointPath != "" {
+		if err := os.Remove(importIndexReq.CheckpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return importIndexResp, err
+		}
+	}
+	logger.Info("index imported", "index", importIndexReq.IndexName,
+		"documents", importIndexResp.DocumentsImported, "skipped", importIndexResp.DocumentsSkipped)
+	return importIndexResp, nil
+}
+
+// newImportReader returns a reader of r, decompressed if it is gzip
+// compressed
+func newImportReader(r io.Reader) (*bufio.Reader, error) {
+	reader := bufio.NewReader(r)
+	magic, err := reader.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(gzipReader), nil
+	}
+	return reader, nil
+}
+
+// newImportUpsertRequest returns the request upserting the exported
+// documents, converting their unchunked embeddings to custom vectors if
+// requested
+func newImportUpsertRequest(importIndexReq *ImportIndexRequest, documents []map[string]interface{}) *UpsertDocumentsRequest {
+	upsertDocumentsReq := &UpsertDocumentsRequest{
+		IndexName:    importIndexReq.IndexName,
+		Documents:    make([]interface{}, len(documents)),
+		TensorFields: importIndexReq.TensorFields,
+	}
+	if importIndexReq.UseExistingTensors {
+		useExistingTensors := true
+		upsertDocumentsReq.UseExistingTensors = &useExistingTensors
+	}
+	var vectors []map[string]interface{}
+	if importIndexReq.CustomVectors {
+		vectors = customVectors(documents)
+	}
+	for i, document := range documents {
+		imported := stripDocumentMetadata(document)
+		if vectors != nil {
+			for name, vector := range vectors[i] {
+				imported[name] = map[string]interface{}{"content": imported[name], "vector": vector}
+				if upsertDocumentsReq.Mappings == nil {
+					upsertDocumentsReq.Mappings = map[string]interface{}{}
+				}
+				upsertDocumentsReq.Mappings[name] = map[string]interface{}{"type": FieldTypeCustomVector}
+			}
+		}
+		upsertDocumentsReq.Documents[i] = imported
+	}
+	// custom vectors must be tensor fields
+	var missing []string
+	for name := range upsertDocumentsReq.Mappings {
+		if !containsString(importIndexReq.TensorFields, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		upsertDocumentsReq.TensorFields = append(append([]string(nil), importIndexReq.TensorFields...), missing...)
+	}
+	return upsertDocumentsReq
+}
+
+// customVectors returns the embeddings of every document by field, for the
+// fields which can be imported as custom vectors: text fields embedded as a
+// single chunk in every document of the batch
+func customVectors(documents []map[string]interface{}) []map[string]interface{} {
+	vectors := make([]map[string]interface{}, len(documents))
+	excluded := map[string]bool{}
+	for i, document := range documents {
+		vectors[i] = map[string]interface{}{}
+		chunks := map[string]int{}
+		facets, _ := document[tensorFacetsField].([]interface{})
+		for _, facet := range facets {
+			facet, ok := facet.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for name, content := range facet {
+				if name == "_embedding" {
+					continue
+				}
+				chunks[name]++
+				if text, ok := document[name].(string); !ok || text != content {
+					excluded[name] = true
+				}
+				vectors[i][name] = facet["_embedding"]
+			}
+		}
+		for name, n := range chunks {
+			if n != 1 {
+				excluded[name] = true
+			}
+		}
+	}
+	// the mapping applies to the whole batch
+	for i, document := range documents {
+		for _, other := range vectors {
+			for name := range other {
+				if _, ok := vectors[i][name]; !ok && document[name] != nil {
+					excluded[name] = true
+				}
+			}
+		}
+	}
+	for _, documentVectors := range vectors {
+		for name := range excluded {
+			delete(documentVectors, name)
+		}
+	}
+	return vectors
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// readImportCheckpoint returns the checkpoint at path, empty if path is
+// empty or does not exist
+func readImportCheckpoint(path string) (importCheckpoint, error) {
+	var checkpoint importCheckpoint
+	if path == "" {
+		return checkpoint, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("error decoding checkpoint %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// writeImportCheckpoint replaces the checkpoint at path, if any
+func writeImportCheckpoint(path string, checkpoint importCheckpoint) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,379 @@
+package marqo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// getMockServerForExport returns a server holding three documents in the
+// index test, with their tensor facets when exposed, whose stats report
+// numberOfDocuments, and recording the number of searches
+func getMockServerForExport(numberOfDocuments int, searches *int32) *httptest.Server {
+	documents := map[string]map[string]interface{}{
+		"1": {"_id": "1", "title": "one"},
+		"2": {"_id": "2", "title": "two"},
+		"3": {"_id": "3", "title": "three"},
+	}
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var resp interface{}
+			switch r.URL.Path {
+			case "/indexes/test/search":
+				var body struct {
+					Limit                int      `json:"limit"`
+					Offset               int      `json:"offset"`
+					AttributesToRetrieve []string `json:"attributesToRetrieve"`
+				}
+				// disabeling lint as this is a mock server
+				// nolint
+				json.NewDecoder(r.Body).Decode(&body)
+				atomic.AddInt32(searches, 1)
+				if !reflect.DeepEqual(body.AttributesToRetrieve, []string{"_id"}) {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				hits := []map[string]interface{}{}
+				for i := body.Offset; i < min(body.Offset+body.Limit, len(documents)); i++ {
+					hits = append(hits, map[string]interface{}{"_id": string(rune('1' + i)), "_score": 1.0})
+				}
+				resp = map[string]interface{}{"hits": hits}
+			case "/indexes/test/documents":
+				var ids []string
+				// disabeling lint as this is a mock server
+				// nolint
+				json.NewDecoder(r.Body).Decode(&ids)
+				var results []map[string]interface{}
+				for _, id := range ids {
+					if _, ok := documents[id]; !ok {
+						results = append(results, map[string]interface{}{"_id": id, "_found": false})
+						continue
+					}
+					document := map[string]interface{}{"_found": true}
+					for key, value := range documents[id] {
+						document[key] = value
+					}
+					if r.URL.Query().Get("expose_facets") == "true" {
+						document["_tensor_facets"] = []map[string]interface{}{{"title": document["title"], "_embedding": []float64{0.5}}}
+					}
+					results = append(results, document)
+				}
+				resp = map[string]interface{}{"results": results}
+			case "/indexes/test/stats":
+				resp = map[string]interface{}{"numberOfDocuments": numberOfDocuments}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			json.NewEncoder(w).Encode(resp)
+		}))
+}
+
+func TestClient_ExportIndex(t *testing.T) {
+	tests := []struct {
+		name              string
+		numberOfDocuments int
+		req               *ExportIndexRequest
+		want              []string
+		wantMissing       int
+		wantSearches      int
+		wantErr           bool
+	}{
+		{
+			name:              "every document in pages",
+			numberOfDocuments: 3,
+			req:               &ExportIndexRequest{IndexName: "test", BatchSize: 2},
+			want: []string{
+				`{"_id":"1","title":"one"}`,
+				`{"_id":"2","title":"two"}`,
+				`{"_id":"3","title":"three"}`,
+			},
+			wantSearches: 2,
+		},
+		{
+			name:              "documents missing from the search",
+			numberOfDocuments: 4,
+			req:               &ExportIndexRequest{IndexName: "test", ExposeFacets: true},
+			want: []string{
+				`{"_id":"1","_tensor_facets":[{"_embedding":[0.5],"title":"one"}],"title":"one"}`,
+				`{"_id":"2","_tensor_facets":[{"_embedding":[0.5],"title":"two"}],"title":"two"}`,
+				`{"_id":"3","_tensor_facets":[{"_embedding":[0.5],"title":"three"}],"title":"three"}`,
+			},
+			// searched again, finding nothing new
+			wantSearches: 2,
+			wantErr:      true,
+		},
+		{
+			name:              "document ids in batches",
+			numberOfDocuments: 3,
+			req:               &ExportIndexRequest{IndexName: "test", DocumentIDs: []string{"1", "2", "3"}, BatchSize: 2},
+			want: []string{
+				`{"_id":"1","title":"one"}`,
+				`{"_id":"2","title":"two"}`,
+				`{"_id":"3","title":"three"}`,
+			},
+		},
+		{
+			name: "document ids with facets gzipped",
+			req:  &ExportIndexRequest{IndexName: "test", DocumentIDs: []string{"3", "1"}, ExposeFacets: true, Gzip: true},
+			want: []string{
+				`{"_id":"3","_tensor_facets":[{"_embedding":[0.5],"title":"three"}],"title":"three"}`,
+				`{"_id":"1","_tensor_facets":[{"_embedding":[0.5],"title":"one"}],"title":"one"}`,
+			},
+		},
+		{
+			name:        "documents not found are counted",
+			req:         &ExportIndexRequest{IndexName: "test", DocumentIDs: []string{"1", "4"}},
+			want:        []string{`{"_id":"1","title":"one"}`},
+			wantMissing: 1,
+		},
+		{
+			name: "empty index",
+			req:  &ExportIndexRequest{IndexName: "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var searches int32
+			mockServer := getMockServerForExport(tt.numberOfDocuments, &searches)
+			defer mockServer.Close()
+
+			c, err := NewClient(mockServer.URL)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			var buf bytes.Buffer
+			exportResp, err := c.ExportIndex(context.Background(), tt.req, &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.ExportIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if exportResp == nil {
+				// rejected before exporting
+				if !errors.Is(err, ErrInvalidArgument) || buf.Len() > 0 {
+					t.Errorf("Client.ExportIndex() error = %v, wrote %d bytes", err, buf.Len())
+				}
+				return
+			}
+			if exportResp.DocumentsExported != len(tt.want) || exportResp.DocumentsMissing != tt.wantMissing {
+				t.Errorf("Client.ExportIndex() = %+v, want %d documents", exportResp, len(tt.want))
+			}
+			if got := atomic.LoadInt32(&searches); got != int32(tt.wantSearches) {
+				t.Errorf("searches = %d, want %d", got, tt.wantSearches)
+			}
+
+			var r io.Reader = &buf
+			if tt.req.Gzip {
+				r, err = gzip.NewReader(&buf)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("io.ReadAll() error = %v", err)
+			}
+			got := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(tt.want) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.ExportIndex() wrote %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// getMockServerForImport returns a server recording the upsert requests of
+// the index test, failing the failAt-th one
+func getMockServerForImport(failAt int, mu *sync.Mutex, upserts *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.URL.Path != "/indexes/test/documents" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			var body map[string]interface{}
+			// disabeling lint as this is a mock server
+			// nolint
+			json.NewDecoder(r.Body).Decode(&body)
+			*upserts = append(*upserts, body)
+			if len(*upserts) == failAt {
+				w.WriteHeader(http.StatusInternalServerError)
+				// disabeling lint as this is a mock server
+				// nolint
+				w.Write([]byte(`{"message": "internal error", "code": "internal"}`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"errors": false, "items": []}`))
+		}))
+}
+
+func TestClient_ImportIndex(t *testing.T) {
+	const export = `{"_id":"1","title":"one","_tensor_facets":[{"title":"one","_embedding":[0.1]}]}
+{"_id":"2","title":"two","_tensor_facets":[{"title":"two","_embedding":[0.2]}]}
+
+{"_id":"3","title":"three","_tensor_facets":[{"title":"three","_embedding":[0.3]}]}
+`
+
+	t.Run("resumes from checkpoint", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			upserts []map[string]interface{}
+		)
+		mockServer := getMockServerForImport(2, &mu, &upserts)
+		defer mockServer.Close()
+
+		c, err := NewClient(mockServer.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		checkpointPath := filepath.Join(t.TempDir(), "import.checkpoint")
+		req := &ImportIndexRequest{IndexName: "test", BatchSize: 1, UseExistingTensors: true, CheckpointPath: checkpointPath}
+
+		importResp, err := c.ImportIndex(context.Background(), req, strings.NewReader(export))
+		if err == nil || importResp.DocumentsImported != 1 {
+			t.Fatalf("Client.ImportIndex() = %+v, %v, want a failure after 1 document", importResp, err)
+		}
+		checkpoint, err := readImportCheckpoint(checkpointPath)
+		if err != nil || checkpoint.Lines != 1 {
+			t.Fatalf("checkpoint = %+v, %v, want 1 line", checkpoint, err)
+		}
+
+		importResp, err = c.ImportIndex(context.Background(), req, strings.NewReader(export))
+		if err != nil {
+			t.Fatalf("Client.ImportIndex() error = %v", err)
+		}
+		if importResp.DocumentsImported != 2 || importResp.DocumentsSkipped != 1 {
+			t.Errorf("Client.ImportIndex() = %+v, want 2 imported and 1 skipped", importResp)
+		}
+		if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+			t.Errorf("checkpoint not removed: %v", err)
+		}
+		var ids []string
+		for _, upsert := range upserts {
+			if upsert["useExistingTensors"] != true {
+				t.Errorf("upsert = %v, want useExistingTensors", upsert)
+			}
+			for _, document := range upsert["documents"].([]interface{}) {
+				document := document.(map[string]interface{})
+				if _, ok := document["_tensor_facets"]; ok {
+					t.Errorf("document = %v, want no tensor facets", document)
+				}
+				ids = append(ids, document["_id"].(string))
+			}
+		}
+		if want := []string{"1", "2", "2", "3"}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("upserted documents = %v, want %v", ids, want)
+		}
+	})
+
+	t.Run("rejects checkpoint of another export", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			upserts []map[string]interface{}
+		)
+		mockServer := getMockServerForImport(2, &mu, &upserts)
+		defer mockServer.Close()
+
+		c, err := NewClient(mockServer.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		checkpointPath := filepath.Join(t.TempDir(), "import.checkpoint")
+		req := &ImportIndexRequest{IndexName: "test", BatchSize: 1, CheckpointPath: checkpointPath}
+		if _, err := c.ImportIndex(context.Background(), req, strings.NewReader(export)); err == nil {
+			t.Fatalf("Client.ImportIndex() error = nil, want a failure after 1 document")
+		}
+
+		for _, other := range []string{strings.Replace(export, `"one"`, `"uno"`, 1), ""} {
+			_, err = c.ImportIndex(context.Background(), req, strings.NewReader(other))
+			if !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("Client.ImportIndex() error = %v, want ErrInvalidArgument", err)
+			}
+		}
+		if len(upserts) != 2 {
+			t.Errorf("upserts = %d, want 2", len(upserts))
+		}
+	})
+
+	t.Run("custom vectors gzipped", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			upserts []map[string]interface{}
+		)
+		mockServer := getMockServerForImport(0, &mu, &upserts)
+		defer mockServer.Close()
+
+		c, err := NewClient(mockServer.URL, WithDialect(DialectV2))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		// disabeling lint as this is a test buffer
+		// nolint
+		gzipWriter.Write([]byte(export))
+		gzipWriter.Close()
+
+		importResp, err := c.ImportIndex(context.Background(), &ImportIndexRequest{IndexName: "test", CustomVectors: true}, &buf)
+		if err != nil {
+			t.Fatalf("Client.ImportIndex() error = %v", err)
+		}
+		if importResp.DocumentsImported != 3 || len(upserts) != 1 {
+			t.Fatalf("Client.ImportIndex() = %+v, upserts = %d", importResp, len(upserts))
+		}
+		want := map[string]interface{}{
+			"documents": []interface{}{
+				map[string]interface{}{"_id": "1", "title": map[string]interface{}{"content": "one", "vector": []interface{}{0.1}}},
+				map[string]interface{}{"_id": "2", "title": map[string]interface{}{"content": "two", "vector": []interface{}{0.2}}},
+				map[string]interface{}{"_id": "3", "title": map[string]interface{}{"content": "three", "vector": []interface{}{0.3}}},
+			},
+			"tensorFields": []interface{}{"title"},
+			"mappings":     map[string]interface{}{"title": map[string]interface{}{"type": "custom_vector"}},
+		}
+		if !reflect.DeepEqual(upserts[0], want) {
+			t.Errorf("upsert = %v, want %v", upserts[0], want)
+		}
+	})
+}
+
+func TestCustomVectors(t *testing.T) {
+	facet := func(field, content string) map[string]interface{} {
+		return map[string]interface{}{field: content, "_embedding": []interface{}{0.1}}
+	}
+	documents := []map[string]interface{}{
+		{"title": "a", "body": "b c", "tags": "x", "_tensor_facets": []interface{}{
+			facet("title", "a"), facet("body", "b"), facet("body", "c"), facet("tags", "x"),
+		}},
+		{"title": "d", "tags": "y", "_tensor_facets": []interface{}{facet("title", "d")}},
+	}
+	got := customVectors(documents)
+	// body is chunked and tags is not embedded in every document
+	want := []map[string]interface{}{
+		{"title": []interface{}{0.1}},
+		{"title": []interface{}{0.1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("customVectors() = %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -49,27 +50,42 @@ type GetIndexHealthResponse struct {
 //	}
 //	fmt.Printf("GetIndexHealthResponse: %+v\n", resp)
 func (c *Client) GetIndexHealth(getIndexHealthReq *GetIndexHealthRequest) (*GetIndexHealthResponse, error) {
-	logger := c.logger.With("method", "GetIndexHealth")
-	err := validate.Struct(getIndexHealthReq)
+	return c.GetIndexHealthWithContext(context.Background(), getIndexHealthReq)
+}
+
+// GetIndexHealthWithContext is like GetIndexHealth but sends the request with ctx.
+func (c *Client) GetIndexHealthWithContext(ctx context.Context, getIndexHealthReq *GetIndexHealthRequest) (_ *GetIndexHealthResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetIndexHealth")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetIndexHealth")
+	c.setDefaultIndex(&getIndexHealthReq.IndexName)
+	err = validate.Struct(getIndexHealthReq)
 	if err != nil {
 		logger.Error("error validating get index health request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, getIndexHealthReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var getIndexHealthResp GetIndexHealthResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetIndexHealth", indexName, getIndexHealthReq).
 		SetSuccessResult(&getIndexHealthResp).
-		Get(c.reqClient.BaseURL + "/indexes/" + getIndexHealthReq.IndexName + "/health")
+		Get(c.reqClient.BaseURL + "/indexes/" + indexName + "/health")
 	if err != nil {
 		logger.Error("error getting index health", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error getting index health", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting index health: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting index health", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting index health: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response index health: %+v", getIndexHealthResp))
+	c.logResponse(ctx, logger, resp, "got index health",
+		"index", indexName,
+		"status", getIndexHealthResp.Status)
 	return &getIndexHealthResp, nil
 }
@@ -0,0 +1,152 @@
+package marqo
+
+import (
+	"context"
+)
+
+// Index is a handle bound to an index, or to an alias when the client has
+// an alias store, with per-index request defaults. It is safe for
+// concurrent use.
+type Index struct {
+	client          *Client
+	name            string
+	tensorFields    []string
+	searchMethod    *string
+	textChunkPrefix *string
+	textQueryPrefix *string
+}
+
+// IndexOption sets a default of the requests of an Index
+type IndexOption func(*Index)
+
+// WithTensorFields sets the tensor fields of the documents added to the
+// index, unless the request has some
+func WithTensorFields(fields ...string) IndexOption {
+	return func(i *Index) {
+		i.tensorFields = fields
+	}
+}
+
+// WithSearchMethod sets the search method of the searches of the index,
+// unless the request has one
+func WithSearchMethod(method string) IndexOption {
+	return func(i *Index) {
+		i.searchMethod = &method
+	}
+}
+
+// WithTextChunkPrefix sets the prefix of the text chunks of the documents
+// added to the index, unless the request has one
+func WithTextChunkPrefix(prefix string) IndexOption {
+	return func(i *Index) {
+		i.textChunkPrefix = &prefix
+	}
+}
+
+// WithTextQueryPrefix sets the prefix of the text queries of the searches
+// of the index, unless the request has one
+func WithTextQueryPrefix(prefix string) IndexOption {
+	return func(i *Index) {
+		i.textQueryPrefix = &prefix
+	}
+}
+
+// Index returns a handle bound to the index, which sets the index name of
+// its requests and fills the defaults set by opts in.
+//
+// Example usage:
+//
+//	products := client.Index("products",
+//	    marqo.WithTensorFields("title", "description"),
+//	    marqo.WithSearchMethod("HYBRID"),
+//	)
+//	_, err := products.AddDocuments(ctx, &marqo.UpsertDocumentsRequest{
+//	    Documents: []interface{}{product},
+//	})
+//	searchResp, err := products.Search(ctx, &marqo.SearchRequest{Q: &query})
+func (c *Client) Index(name string, opts ...IndexOption) *Index {
+	i := &Index{client: c, name: name}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Name returns the name of the index
+func (i *Index) Name() string {
+	return i.name
+}
+
+// Search searches the index, see Client.SearchWithContext. The search
+// method and text query prefix of the index are used unless searchReq sets
+// them.
+func (i *Index) Search(ctx context.Context, searchReq *SearchRequest) (*SearchResponse, error) {
+	req := *searchReq
+	req.IndexName = i.name
+	if req.SearchMethod == nil {
+		req.SearchMethod = i.searchMethod
+	}
+	if req.TextQueryPrefix == nil {
+		req.TextQueryPrefix = i.textQueryPrefix
+	}
+	return i.client.SearchWithContext(ctx, &req)
+}
+
+// AddDocuments upserts documents to the index, see
+// Client.UpsertDocumentsWithContext. The tensor fields and text chunk
+// prefix of the index are used unless upsertDocumentsReq sets them.
+func (i *Index) AddDocuments(ctx context.Context, upsertDocumentsReq *UpsertDocumentsRequest) (*UpsertDocumentsResponse, error) {
+	req := *upsertDocumentsReq
+	req.IndexName = i.name
+	if req.TensorFields == nil {
+		req.TensorFields = i.tensorFields
+	}
+	if req.TextChunkPrefix == nil {
+		req.TextChunkPrefix = i.textChunkPrefix
+	}
+	return i.client.UpsertDocumentsWithContext(ctx, &req)
+}
+
+// GetDocument gets a document of the index, see Client.GetDocumentWithContext
+func (i *Index) GetDocument(ctx context.Context, documentID string) (*GetDocumentResponse, error) {
+	return i.client.GetDocumentWithContext(ctx, &GetDocumentRequest{IndexName: i.name, DocumentID: documentID})
+}
+
+// GetDocuments gets documents of the index, see Client.GetDocumentsWithContext
+func (i *Index) GetDocuments(ctx context.Context, documentIDs ...string) (*GetDocumentsResponse, error) {
+	return i.client.GetDocumentsWithContext(ctx, &GetDocumentsRequest{IndexName: i.name, DocumentIDs: documentIDs})
+}
+
+// DeleteDocuments deletes documents of the index, see
+// Client.DeleteDocumentsWithContext
+func (i *Index) DeleteDocuments(ctx context.Context, documentIDs ...string) (*DeleteDocumentsResponse, error) {
+	return i.client.DeleteDocumentsWithContext(ctx, &DeleteDocumentsRequest{IndexName: i.name, DocumentIDs: documentIDs})
+}
+
+// Delete deletes the index, see Client.DeleteIndexWithContext. If the
+// handle is bound to an alias, it returns ErrInvalidArgument rather than
+// deleting the index the alias points to or shadows.
+func (i *Index) Delete(ctx context.Context) (*DeleteIndexResponse, error) {
+	return i.client.DeleteIndexWithContext(ctx, &DeleteIndexRequest{IndexName: i.name})
+}
+
+// Stats gets the stats of the index, see Client.GetIndexStatsWithContext
+func (i *Index) Stats(ctx context.Context) (*GetIndexStatsResponse, error) {
+	return i.client.GetIndexStatsWithContext(ctx, &GetIndexStatsRequest{IndexName: i.name})
+}
+
+// Health gets the health of the index, see Client.GetIndexHealthWithContext
+func (i *Index) Health(ctx context.Context) (*GetIndexHealthResponse, error) {
+	return i.client.GetIndexHealthWithContext(ctx, &GetIndexHealthRequest{IndexName: i.name})
+}
+
+// Settings gets the settings of the index, see
+// Client.GetIndexSettingsWithContext
+func (i *Index) Settings(ctx context.Context) (*GetIndexSettingsResponse, error) {
+	return i.client.GetIndexSettingsWithContext(ctx, &GetIndexSettingsRequest{IndexName: i.name})
+}
+
+// Refresh refreshes the index, see Client.RefreshIndexWithContext
+func (i *Index) Refresh(ctx context.Context) (*RefreshIndexResponse, error) {
+	return i.client.RefreshIndexWithContext(ctx, &RefreshIndexRequest{IndexName: i.name})
+}
@@ -0,0 +1,138 @@
+package marqo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// getMockServerForIndex returns a server recording the method, path and
+// body of the requests
+func getMockServerForIndex(mu *sync.Mutex, requests *[]string, bodies *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			*requests = append(*requests, r.Method+" "+r.URL.Path)
+			var body map[string]interface{}
+			// disabeling lint as this is a mock server
+			// nolint
+			json.NewDecoder(r.Body).Decode(&body)
+			*bodies = append(*bodies, body)
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{}`))
+		}))
+}
+
+func TestClient_Index(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		bodies   []map[string]interface{}
+	)
+	mockServer := getMockServerForIndex(&mu, &requests, &bodies)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL, WithDialect(DialectV2))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	products := c.Index("products",
+		WithTensorFields("title"),
+		WithSearchMethod("HYBRID"),
+		WithTextChunkPrefix("passage: "),
+		WithTextQueryPrefix("query: "),
+	)
+	if products.Name() != "products" {
+		t.Errorf("Index.Name() = %s", products.Name())
+	}
+
+	ctx := context.Background()
+	q, lexical := "shoes", "LEXICAL"
+	searchReq := &SearchRequest{Q: &q}
+	steps := []func() error{
+		func() error { _, err := products.Search(ctx, searchReq); return err },
+		func() error {
+			_, err := products.Search(ctx, &SearchRequest{Q: &q, SearchMethod: &lexical})
+			return err
+		},
+		func() error {
+			_, err := products.AddDocuments(ctx, &UpsertDocumentsRequest{Documents: []interface{}{map[string]string{"title": "a"}}})
+			return err
+		},
+		func() error { _, err := products.GetDocument(ctx, "1"); return err },
+		func() error { _, err := products.GetDocuments(ctx, "1", "2"); return err },
+		func() error { _, err := products.DeleteDocuments(ctx, "1"); return err },
+		func() error { _, err := products.Stats(ctx); return err },
+		func() error { _, err := products.Health(ctx); return err },
+		func() error { _, err := products.Settings(ctx); return err },
+		func() error { _, err := products.Delete(ctx); return err },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d error = %v", i, err)
+		}
+	}
+	if searchReq.IndexName != "" || searchReq.SearchMethod != nil {
+		t.Errorf("Index.Search() modified the request: %+v", searchReq)
+	}
+
+	wantRequests := []string{
+		"POST /indexes/products/search",
+		"POST /indexes/products/search",
+		"POST /indexes/products/documents",
+		"GET /indexes/products/documents/1",
+		"GET /indexes/products/documents",
+		"POST /indexes/products/documents/delete-batch",
+		"GET /indexes/products/stats",
+		"GET /indexes/products/health",
+		"GET /indexes/products/settings",
+		"DELETE /indexes/products",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Fatalf("requests = %v, want %v", requests, wantRequests)
+	}
+	if bodies[0]["searchMethod"] != "HYBRID" || bodies[0]["textQueryPrefix"] != "query: " {
+		t.Errorf("search body = %v, want the index defaults", bodies[0])
+	}
+	if bodies[1]["searchMethod"] != "LEXICAL" {
+		t.Errorf("search body = %v, want the request search method", bodies[1])
+	}
+	if !reflect.DeepEqual(bodies[2]["tensorFields"], []interface{}{"title"}) || bodies[2]["textChunkPrefix"] != "passage: " {
+		t.Errorf("upsert body = %v, want the index defaults", bodies[2])
+	}
+}
+
+func TestClient_IndexDeleteAlias(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		bodies   []map[string]interface{}
+	)
+	mockServer := getMockServerForIndex(&mu, &requests, &bodies)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL, WithAliasStore(NewMemoryAliasStore()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := c.SetAlias(context.Background(), "products", "products-v1"); err != nil {
+		t.Fatalf("SetAlias() error = %v", err)
+	}
+	if _, err := c.Index("products").Delete(context.Background()); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("Index.Delete() error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.Index("products-v1").Delete(context.Background()); err != nil {
+		t.Fatalf("Index.Delete() error = %v", err)
+	}
+	if want := []string{"DELETE /indexes/products-v1"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
@@ -1,6 +1,8 @@
 package marqo
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -13,13 +15,32 @@ type CreateIndexRequest struct {
 	NumberOfShards *int `json:"number_of_shards,omitempty"`
 	// Number of replicas for the index (default: 0)
 	NumberOfReplicas *int `json:"number_of_replicas,omitempty"`
+
+	// Marqo 2.x only settings, sent by the Marqo 2.x dialect
+
+	// Type of the index, IndexTypeUnstructured or IndexTypeStructured
+	// (default: unstructured)
+	Type *string `json:"-"`
+	// AllFields are the fields of a structured index
+	AllFields []FieldDefinition `json:"-" validate:"dive"`
+	// TensorFields are the fields of a structured index which are vectorised,
+	// they must be in AllFields
+	TensorFields []string `json:"-"`
+	// VectorNumericType is the numeric type of the vectors, "float" or
+	// "bfloat16" (default: float)
+	VectorNumericType *string `json:"-"`
+
+	// Wait makes CreateIndex wait until the index is ready, see WaitForIndexReady
+	Wait bool `json:"-"`
 }
 
 // IndexDefaults is the defaults for the index
 type IndexDefaults struct {
 	// Fetch images from points and URLs (default: false)
 	TreatURLsAndPointersAsImages *bool `json:"treat_urls_and_pointers_as_images,omitempty"`
-	// Model to vectorize doc content (default: hf/all_datasets_v4_MiniLM-L6)
+	// Model to vectorize doc content (default: DefaultModel). Custom models
+	// need ModelProperties with their dimensions, other models missing from
+	// the catalog, see Models, are validated by the server.
 	Model           *string          `json:"model,omitempty"`
 	ModelProperties *ModelProperties `json:"model_properties,omitempty"`
 	// TODO: add search model support in the future
@@ -60,7 +81,8 @@ type ImagePreprocessing struct {
 
 // ANNParameters are the ANN parameters for the index
 type ANNParameters struct {
-	// The function used to measure the distance between two points in ANN (l1, l2, linf, or cosinesimil. default: cosinesimil)
+	// The function used to measure the distance between two points in ANN (l1, l2, linf, or cosinesimil. default: cosinesimil).
+	// Marqo 2.x also accepts its own space types (euclidean, angular, dotproduct, prenormalized-angular or hamming).
 	SpaceType *string `json:"space_type,omitempty"`
 	// The hyperparameters for the ANN method (which is always hnsw for Marqo).
 	Parameters *HSNWMethodParameters `json:"parameters,omitempty"`
@@ -110,7 +132,7 @@ func setDefaultCreateIndexRequest(createIndexReq *CreateIndexRequest) {
 
 	if createIndexReq.IndexDefaults.Model == nil {
 		createIndexReq.IndexDefaults.Model = new(string)
-		*createIndexReq.IndexDefaults.Model = "hf/all_datasets_v4_MiniLM-L6"
+		*createIndexReq.IndexDefaults.Model = string(DefaultModel)
 	}
 
 	// TODO: add search model support in the future
@@ -187,10 +209,15 @@ func setDefaultCreateIndexRequest(createIndexReq *CreateIndexRequest) {
 //
 // The function performs the following steps:
 // 1. Sets default values for the createIndexReq parameter.
-// 2. Validates the createIndexReq parameter.
-// 3. Sends a POST request to the server with the index details in the request body.
-// 4. Checks the response status code and logs any errors.
-// 5. Returns the response from the server if the operation is successful, otherwise returns an error.
+// 2. Validates the createIndexReq parameter, including the fields of structured indexes and
+// the model against the model catalog, and logs a warning for settings the model does not support
+// and for models missing from the catalog.
+// 3. Returns ErrUnsupportedOperation for a structured index if the server is Marqo 1.x.
+// 4. Sends a POST request to the server with the index details in the request body,
+// encoded for the dialect of the server.
+// 5. Checks the response status code and logs any errors.
+// 6. Waits until the index is ready if createIndexReq.Wait is set.
+// 7. Returns the response from the server if the operation is successful, otherwise returns an error.
 //
 // Example usage:
 //
@@ -203,19 +230,53 @@ func setDefaultCreateIndexRequest(createIndexReq *CreateIndexRequest) {
 //	}
 //	fmt.Printf("CreateIndexResponse: %+v\n", resp)
 func (c *Client) CreateIndex(createIndexReq *CreateIndexRequest) (*CreateIndexResponse, error) {
-	logger := c.logger.With("method", "CreateIndex")
+	return c.CreateIndexWithContext(context.Background(), createIndexReq)
+}
+
+// CreateIndexWithContext is like CreateIndex but sends the request with ctx.
+func (c *Client) CreateIndexWithContext(ctx context.Context, createIndexReq *CreateIndexRequest) (_ *CreateIndexResponse, err error) {
+	ctx, span := c.startSpan(ctx, "CreateIndex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("CreateIndex")
 	setDefaultCreateIndexRequest(createIndexReq)
-	err := validate.Struct(createIndexReq)
+	err = validate.Struct(createIndexReq)
 	if err != nil {
 		logger.Error("error validating create index request",
 			"error", err)
 		return nil, err
 	}
+	err = validateIndexSchema(createIndexReq)
+	if err != nil {
+		logger.Error("error validating create index request",
+			"error", err)
+		return nil, err
+	}
+	warnings, err := validateModel(createIndexReq)
+	if err != nil {
+		logger.Error("error validating create index request",
+			"error", err)
+		return nil, err
+	}
+	for _, warning := range warnings {
+		logger.Warn(warning, "index", createIndexReq.IndexName)
+	}
+
+	dialect, err := c.DetectDialect(ctx)
+	if err != nil {
+		logger.Error("error detecting dialect", "error", err)
+		return nil, err
+	}
+	var body interface{} = createIndexReq
+	if dialect == DialectV2 {
+		body = newCreateIndexRequestV2(createIndexReq)
+	} else if createIndexReq.Type != nil && *createIndexReq.Type == IndexTypeStructured {
+		logger.Error("error creating index", "error", ErrUnsupportedOperation)
+		return nil, fmt.Errorf("error creating structured index: %w", ErrUnsupportedOperation)
+	}
 
 	var createIndexResp CreateIndexResponse
-	resp, err := c.reqClient.
-		R().
-		SetBody(createIndexReq).
+	resp, err := c.newRequest(ctx, "CreateIndex", createIndexReq.IndexName, createIndexReq).
+		SetBody(body).
 		SetSuccessResult(&createIndexResp).
 		Post(c.reqClient.BaseURL + "/indexes/" + createIndexReq.IndexName)
 	if err != nil {
@@ -223,13 +284,20 @@ func (c *Client) CreateIndex(createIndexReq *CreateIndexRequest) (*CreateIndexRe
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error creating index", "status_code", resp.
-			Response.StatusCode)
-		return nil, fmt.Errorf("error creating index: status code: %v",
-			resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error creating index", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error creating index: %w", apiErr)
 	}
 
-	logger.Info("index created")
+	c.logResponse(ctx, logger, resp, "index created",
+		"index", createIndexReq.IndexName)
+	if createIndexReq.Wait {
+		// the created index is physical, even if an alias has its name
+		err = c.waitForIndex(withPhysicalIndex(ctx), createIndexReq.IndexName, newWaitSettings(nil), c.pollIndexReady)
+		if err != nil {
+			return &createIndexResp, err
+		}
+	}
 	return &createIndexResp, nil
 }
 
@@ -246,6 +314,8 @@ type DeleteIndexResponse struct {
 // DeleteIndex deletes an index
 //
 // This method sends a DELETE request to the server to delete the specified index.
+// Aliases are not resolved: with an alias store, an index name which is an alias
+// is rejected instead of deleting the index of the same name it shadows.
 //
 // Parameters:
 //
@@ -257,7 +327,7 @@ type DeleteIndexResponse struct {
 //	error: An error if the operation fails, otherwise nil.
 //
 // The function performs the following steps:
-// 1. Validates the deleteIndexRequest parameter.
+// 1. Validates the deleteIndexRequest parameter and returns ErrInvalidArgument if the index name is an alias.
 // 2. Sends a DELETE request to the server with the index name as a query parameter.
 // 3. Checks the response status code and logs any errors.
 // 4. Returns the response from the server if the operation is successful, otherwise returns an error.
@@ -273,17 +343,38 @@ type DeleteIndexResponse struct {
 //	}
 //	fmt.Printf("DeleteIndexResponse: %+v\n", resp)
 func (c *Client) DeleteIndex(deleteIndexRequest *DeleteIndexRequest) (*DeleteIndexResponse, error) {
-	logger := c.logger.With("method", "DeleteIndex")
-	err := validate.Struct(deleteIndexRequest)
+	return c.DeleteIndexWithContext(context.Background(), deleteIndexRequest)
+}
+
+// DeleteIndexWithContext is like DeleteIndex but sends the request with ctx.
+func (c *Client) DeleteIndexWithContext(ctx context.Context, deleteIndexRequest *DeleteIndexRequest) (*DeleteIndexResponse, error) {
+	return c.deleteIndex(ctx, deleteIndexRequest, true)
+}
+
+// deleteIndex deletes the index, and returns ErrInvalidArgument if it is
+// an alias and rejectAliases is set. Without rejectAliases, the physical
+// index is deleted whether or not an alias has its name.
+func (c *Client) deleteIndex(ctx context.Context, deleteIndexRequest *DeleteIndexRequest, rejectAliases bool) (_ *DeleteIndexResponse, err error) {
+	ctx, span := c.startSpan(ctx, "DeleteIndex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("DeleteIndex")
+	err = validate.Struct(deleteIndexRequest)
 	if err != nil {
 		logger.Error("error validating delete index request",
 			"error", err)
 		return nil, err
 	}
+	if rejectAliases {
+		err = c.rejectAlias(ctx, deleteIndexRequest.IndexName)
+		if err != nil {
+			logger.Error("error validating delete index request",
+				"error", err)
+			return nil, err
+		}
+	}
 
 	var deleteIndexResp DeleteIndexResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "DeleteIndex", deleteIndexRequest.IndexName, deleteIndexRequest).
 		SetSuccessResult(&deleteIndexResp).
 		Delete(c.reqClient.BaseURL + "/indexes/" + deleteIndexRequest.IndexName)
 	if err != nil {
@@ -291,13 +382,13 @@ func (c *Client) DeleteIndex(deleteIndexRequest *DeleteIndexRequest) (*DeleteInd
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error deleting index", "status_code", resp.
-			Response.StatusCode)
-		return nil, fmt.Errorf("error deleting index: status code: %v",
-			resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error deleting index", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error deleting index: %w", apiErr)
 	}
 
-	logger.Info("index deleted")
+	c.logResponse(ctx, logger, resp, "index deleted",
+		"index", deleteIndexRequest.IndexName)
 	return &deleteIndexResp, nil
 }
 
@@ -309,15 +400,41 @@ type ListIndexesResponse struct {
 // Result is the result for listing one index
 type Result struct {
 	IndexName string `json:"index_name"`
+	// IndexStatus is the status of the index on Marqo Cloud, e.g. READY or
+	// CREATING, and empty for other servers
+	IndexStatus string `json:"indexStatus,omitempty"`
+}
+
+// UnmarshalJSON decodes the index name of Marqo Cloud, which is camelCase
+func (r *Result) UnmarshalJSON(data []byte) error {
+	type result Result
+	var decoded struct {
+		result
+		CloudIndexName string `json:"indexName"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = Result(decoded.result)
+	if r.IndexName == "" {
+		r.IndexName = decoded.CloudIndexName
+	}
+	return nil
 }
 
 // ListIndexes lists the indexes
 func (c *Client) ListIndexes() (*ListIndexesResponse, error) {
-	logger := c.logger.With("method", "ListIndexes")
+	return c.ListIndexesWithContext(context.Background())
+}
+
+// ListIndexesWithContext is like ListIndexes but sends the request with ctx.
+func (c *Client) ListIndexesWithContext(ctx context.Context) (_ *ListIndexesResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ListIndexes")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("ListIndexes")
 	var result ListIndexesResponse
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "ListIndexes", "", nil).
 		SetSuccessResult(&result).
 		Get(c.reqClient.BaseURL + "/indexes")
 	if err != nil {
@@ -325,12 +442,12 @@ func (c *Client) ListIndexes() (*ListIndexesResponse, error) {
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error listing indexes", "status_code", resp.
-			Response.StatusCode)
-		return nil, fmt.Errorf("error listing indexes: status code: %v",
-			resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error listing indexes", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error listing indexes: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response indexes: %+v", result))
+	c.logResponse(ctx, logger, resp, "listed indexes",
+		"indexes", len(result.Results))
 	return &result, nil
 }
@@ -0,0 +1,159 @@
+package marqo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// defaultLogPayloadLimit is the default maximum size of a logged payload in bytes
+const defaultLogPayloadLimit = 4096
+
+// redactedValue replaces the values of the redacted fields in the logs
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedFields are the payload fields always redacted in the logs
+var defaultRedactedFields = []string{
+	"modelAuth",
+	"model_auth",
+	"imageDownloadHeaders",
+	"image_download_headers",
+	"x-api-key",
+	"apiKey",
+	"api_key",
+}
+
+// WithLogPayloadLimit sets the maximum size in bytes of the request and
+// response payloads logged at Debug level, longer payloads are truncated
+// (default: 4096). A negative limit disables payload logging.
+func WithLogPayloadLimit(limit int) func(*Client) {
+	return func(c *Client) {
+		c.logPayloadLimit = limit
+	}
+}
+
+// WithRedactedFields adds payload fields whose values are replaced with
+// "[REDACTED]" in the logs, at any depth. The modelAuth and image download
+// headers fields and the API key are always redacted.
+func WithRedactedFields(fields ...string) func(*Client) {
+	return func(c *Client) {
+		for _, field := range fields {
+			c.redactedFields[field] = true
+		}
+	}
+}
+
+// WithOperationLogLevel sets the minimum level of the logs of one client
+// method, e.g. "Search" or "UpsertDocuments", overriding the level of the
+// logger handler in both directions. It can be used to quiet a noisy
+// method or to debug a single one.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithLogger(logger),
+//	    marqo.WithOperationLogLevel("Search", slog.LevelDebug),
+//	    marqo.WithOperationLogLevel("UpsertDocuments", slog.LevelWarn),
+//	)
+func WithOperationLogLevel(operation string, level slog.Level) func(*Client) {
+	return func(c *Client) {
+		c.operationLogLevels[operation] = level
+	}
+}
+
+// methodLogger returns the logger of a client method
+func (c *Client) methodLogger(name string) *slog.Logger {
+	logger := c.logger
+	if level, ok := c.operationLogLevels[name]; ok {
+		logger = slog.New(&levelHandler{handler: logger.Handler(), level: level})
+	}
+	return logger.With("method", name)
+}
+
+// levelHandler is a slog.Handler which replaces the minimum level of the
+// wrapped handler
+type levelHandler struct {
+	handler slog.Handler
+	level   slog.Level
+}
+
+// Enabled implements slog.Handler
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+// Handle implements slog.Handler
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{handler: h.handler.WithAttrs(attrs), level: h.level}
+}
+
+// WithGroup implements slog.Handler
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{handler: h.handler.WithGroup(name), level: h.level}
+}
+
+// logResponse logs msg with attrs and the duration of the operation at
+// Info level, and the redacted request and response payloads at Debug
+// level
+func (c *Client) logResponse(ctx context.Context, logger *slog.Logger, resp *req.Response, msg string, attrs ...any) {
+	if logger.Enabled(ctx, slog.LevelInfo) {
+		op := operationFromContext(resp.Request.Context())
+		if !op.start.IsZero() {
+			attrs = append(attrs, "duration", time.Since(op.start))
+		}
+		logger.InfoContext(ctx, msg, attrs...)
+	}
+	if c.logPayloadLimit >= 0 && logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, msg+" payload",
+			"request", c.logPayload(resp.Request.Body),
+			"response", c.logPayload(resp.Bytes()))
+	}
+}
+
+// logPayload returns the payload with the sensitive fields redacted,
+// truncated to the payload limit
+func (c *Client) logPayload(payload []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err == nil {
+		if redacted, err := json.Marshal(c.redact(value)); err == nil {
+			payload = redacted
+		}
+	}
+	limit := c.logPayloadLimit
+	if limit == 0 {
+		limit = defaultLogPayloadLimit
+	}
+	if len(payload) > limit {
+		return fmt.Sprintf("%s...(%d more bytes)", payload[:limit], len(payload)-limit)
+	}
+	return string(payload)
+}
+
+// redact replaces the values of the redacted fields of a decoded JSON value
+func (c *Client) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if c.redactedFields[key] || c.redactedFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = c.redact(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = c.redact(item)
+		}
+	}
+	return value
+}
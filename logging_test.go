@@ -0,0 +1,133 @@
+package marqo
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// logRecords decodes the JSON log records in buf
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogging(t *testing.T) {
+	mockServer := getMockServerForError(http.StatusOK,
+		`{"hits": [{"_id": "1", "text": "`+strings.Repeat("a", 200)+`"}, {"_id": "2"}], "processingTimeMs": 8}`)
+	defer mockServer.Close()
+	query := "shoes"
+	searchReq := func() *SearchRequest {
+		return &SearchRequest{
+			IndexName:            "products",
+			Q:                    &query,
+			ModelAuth:            map[string]interface{}{"s3": map[string]interface{}{"aws_secret_access_key": "model-secret"}},
+			ImageDownloadHeaders: map[string]interface{}{"Authorization": "image-secret"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		level       slog.Level
+		opt         []Options
+		wantRecords int
+		wantPayload bool
+		// wantTruncated is true if the payloads are truncated and the query redacted
+		wantTruncated bool
+	}{
+		{
+			name:        "info logs counts only",
+			level:       slog.LevelInfo,
+			wantRecords: 1,
+		},
+		{
+			name:          "debug logs redacted payloads",
+			level:         slog.LevelDebug,
+			opt:           []Options{WithLogPayloadLimit(100), WithRedactedFields("q")},
+			wantRecords:   2,
+			wantPayload:   true,
+			wantTruncated: true,
+		},
+		{
+			name:        "operation level lowers the logger level",
+			level:       slog.LevelError,
+			opt:         []Options{WithOperationLogLevel("Search", slog.LevelDebug)},
+			wantRecords: 2,
+			wantPayload: true,
+		},
+		{
+			name:        "operation level raises the logger level",
+			level:       slog.LevelDebug,
+			opt:         []Options{WithOperationLogLevel("Search", slog.LevelWarn)},
+			wantRecords: 0,
+		},
+		{
+			name:        "payload logging disabled",
+			level:       slog.LevelDebug,
+			opt:         []Options{WithLogPayloadLimit(-1)},
+			wantRecords: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+			opt := append([]Options{WithLogger(logger), WithMarqoCloudAuth("api-secret")}, tt.opt...)
+			c, err := NewClient(mockServer.URL, opt...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if _, err := c.Search(searchReq()); err != nil {
+				t.Fatalf("Client.Search() error = %v", err)
+			}
+
+			for _, secret := range []string{"api-secret", "model-secret", "image-secret"} {
+				if strings.Contains(buf.String(), secret) {
+					t.Errorf("logs contain %q, want it redacted", secret)
+				}
+			}
+			records := logRecords(t, &buf)
+			if len(records) != tt.wantRecords {
+				t.Fatalf("log records = %v, want %v: %v", len(records), tt.wantRecords, buf.String())
+			}
+			if tt.wantRecords == 0 {
+				return
+			}
+			info := records[0]
+			if info["msg"] != "search done" || info["hits"] != float64(2) || info["method"] != "Search" {
+				t.Errorf("info record = %v, want search done with 2 hits", info)
+			}
+			if _, ok := info["duration"]; !ok {
+				t.Errorf("info record = %v, want a duration", info)
+			}
+			if !tt.wantPayload {
+				return
+			}
+			payload := records[1]
+			request, _ := payload["request"].(string)
+			response, _ := payload["response"].(string)
+			if !strings.Contains(request, redactedValue) {
+				t.Errorf("request payload = %v, want redacted fields", request)
+			}
+			if strings.Contains(request, "shoes") != !tt.wantTruncated {
+				t.Errorf("request payload = %v, want query redacted %v", request, tt.wantTruncated)
+			}
+			if strings.HasSuffix(response, "more bytes)") != tt.wantTruncated {
+				t.Errorf("response payload = %v, want truncated %v", response, tt.wantTruncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package marqoprom exports the metrics of the Marqo client to Prometheus.
+//
+// Example usage:
+//
+//	collector := marqoprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	client, err := marqo.NewClient("http://localhost:8882", marqo.WithMetrics(collector))
+package marqoprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	marqo "github.com/ganeshdipdumbare/marqo-go"
+)
+
+// Options for the collector
+type Options func(*Collector)
+
+// WithNamespace sets the namespace of the metric names (default: "marqo_client")
+func WithNamespace(namespace string) func(*Collector) {
+	return func(c *Collector) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets the histogram buckets in seconds of the request
+// duration and the server processing time (default: prometheus.DefBuckets)
+func WithBuckets(buckets []float64) func(*Collector) {
+	return func(c *Collector) {
+		c.buckets = buckets
+	}
+}
+
+// WithConstLabels sets labels added to every metric, e.g. the cluster name
+func WithConstLabels(labels prometheus.Labels) func(*Collector) {
+	return func(c *Collector) {
+		c.constLabels = labels
+	}
+}
+
+// Collector is a prometheus.Collector and a marqo.MetricsRecorder which
+// exposes the following series, labelled by operation and index:
+//
+//	<namespace>_requests_total
+//	<namespace>_request_errors_total (also labelled by error_type)
+//	<namespace>_request_duration_seconds
+//	<namespace>_documents_upserted_total (labelled by index only)
+//	<namespace>_processing_time_seconds
+type Collector struct {
+	namespace   string
+	buckets     []float64
+	constLabels prometheus.Labels
+
+	requests          *prometheus.CounterVec
+	errors            *prometheus.CounterVec
+	duration          *prometheus.HistogramVec
+	documentsUpserted *prometheus.CounterVec
+	processingTime    *prometheus.HistogramVec
+}
+
+// NewCollector creates a new collector, which must be registered with a
+// prometheus registry and passed to the client with marqo.WithMetrics.
+func NewCollector(opt ...Options) *Collector {
+	c := &Collector{
+		namespace: "marqo_client",
+		buckets:   prometheus.DefBuckets,
+	}
+	for _, o := range opt {
+		o(c)
+	}
+
+	c.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   c.namespace,
+		Name:        "requests_total",
+		Help:        "Number of Marqo operations.",
+		ConstLabels: c.constLabels,
+	}, []string{"operation", "index"})
+	c.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   c.namespace,
+		Name:        "request_errors_total",
+		Help:        "Number of failed Marqo operations by error type.",
+		ConstLabels: c.constLabels,
+	}, []string{"operation", "index", "error_type"})
+	c.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   c.namespace,
+		Name:        "request_duration_seconds",
+		Help:        "Duration of Marqo operations, including retries.",
+		Buckets:     c.buckets,
+		ConstLabels: c.constLabels,
+	}, []string{"operation", "index"})
+	c.documentsUpserted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   c.namespace,
+		Name:        "documents_upserted_total",
+		Help:        "Number of documents upserted successfully.",
+		ConstLabels: c.constLabels,
+	}, []string{"index"})
+	c.processingTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   c.namespace,
+		Name:        "processing_time_seconds",
+		Help:        "Processing time reported by the Marqo server.",
+		Buckets:     c.buckets,
+		ConstLabels: c.constLabels,
+	}, []string{"operation", "index"})
+	return c
+}
+
+// RecordOperation implements marqo.MetricsRecorder
+func (c *Collector) RecordOperation(metrics marqo.OperationMetrics) {
+	c.requests.WithLabelValues(metrics.Operation, metrics.IndexName).Inc()
+	c.duration.WithLabelValues(metrics.Operation, metrics.IndexName).Observe(metrics.Duration.Seconds())
+	if metrics.ErrorType != "" {
+		c.errors.WithLabelValues(metrics.Operation, metrics.IndexName, metrics.ErrorType).Inc()
+	}
+	if metrics.DocumentsUpserted > 0 {
+		c.documentsUpserted.WithLabelValues(metrics.IndexName).Add(float64(metrics.DocumentsUpserted))
+	}
+	if metrics.ProcessingTimeMS != nil {
+		c.processingTime.WithLabelValues(metrics.Operation, metrics.IndexName).Observe(*metrics.ProcessingTimeMS / 1000)
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+	c.documentsUpserted.Describe(ch)
+	c.processingTime.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+	c.documentsUpserted.Collect(ch)
+	c.processingTime.Collect(ch)
+}
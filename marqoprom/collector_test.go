@@ -0,0 +1,90 @@
+package marqoprom
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	marqo "github.com/ganeshdipdumbare/marqo-go"
+)
+
+// getMockServer returns a server which responds to the upserts and
+// searches, and with an index not found error otherwise
+func getMockServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/documents"):
+				w.WriteHeader(http.StatusOK)
+				// disabeling lint as this is a mock server
+				// nolint
+				w.Write([]byte(`{"errors": true, "items": [{"_id": "1", "status": 200}, {"_id": "2", "status": 400}], "processingTimeMs": 250}`))
+			case strings.HasSuffix(r.URL.Path, "/search"):
+				w.WriteHeader(http.StatusOK)
+				// nolint
+				w.Write([]byte(`{"hits": [], "processingTimeMs": 20}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				// nolint
+				w.Write([]byte(`{"code": "index_not_found", "type": "invalid_request", "message": "index not found"}`))
+			}
+		}))
+}
+
+func TestCollector(t *testing.T) {
+	mockServer := getMockServer()
+	defer mockServer.Close()
+
+	collector := NewCollector(WithNamespace("test"))
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	c, err := marqo.NewClient(mockServer.URL, marqo.WithMetrics(collector))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	query := "query"
+	for i := 0; i < 2; i++ {
+		if _, err := c.Search(&marqo.SearchRequest{IndexName: "products", Q: &query}); err != nil {
+			t.Fatalf("Client.Search() error = %v", err)
+		}
+	}
+	if _, err := c.UpsertDocuments(&marqo.UpsertDocumentsRequest{
+		IndexName: "products",
+		Documents: []interface{}{map[string]interface{}{"_id": "1"}, map[string]interface{}{"_id": "2"}},
+	}); err != nil {
+		t.Fatalf("Client.UpsertDocuments() error = %v", err)
+	}
+	// nolint
+	c.GetIndexStats(&marqo.GetIndexStatsRequest{IndexName: "missing"})
+
+	want := `
+# HELP test_documents_upserted_total Number of documents upserted successfully.
+# TYPE test_documents_upserted_total counter
+test_documents_upserted_total{index="products"} 1
+# HELP test_request_errors_total Number of failed Marqo operations by error type.
+# TYPE test_request_errors_total counter
+test_request_errors_total{error_type="index_not_found",index="missing",operation="GetIndexStats"} 1
+# HELP test_requests_total Number of Marqo operations.
+# TYPE test_requests_total counter
+test_requests_total{index="missing",operation="GetIndexStats"} 1
+test_requests_total{index="products",operation="Search"} 2
+test_requests_total{index="products",operation="UpsertDocuments"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want),
+		"test_documents_upserted_total", "test_request_errors_total", "test_requests_total"); err != nil {
+		t.Errorf("GatherAndCompare() error = %v", err)
+	}
+	if got := testutil.CollectAndCount(collector, "test_processing_time_seconds"); got != 2 {
+		t.Errorf("processing time series = %v, want 2", got)
+	}
+	if got := testutil.CollectAndCount(collector, "test_request_duration_seconds"); got != 3 {
+		t.Errorf("request duration series = %v, want 3", got)
+	}
+}
@@ -0,0 +1,114 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// MetricsRecorder receives the metrics of the client operations, e.g. to
+// export them to Prometheus with the marqoprom package. It is called once
+// per operation, after retries and failovers, and must be safe for
+// concurrent use.
+type MetricsRecorder interface {
+	RecordOperation(metrics OperationMetrics)
+}
+
+// OperationMetrics are the metrics of one client operation
+type OperationMetrics struct {
+	// Operation is the name of the client method, e.g. "Search"
+	Operation string
+	// IndexName is the index of the operation, empty if none
+	IndexName string
+	// Duration is the time taken by the operation
+	Duration time.Duration
+	// StatusCode is the HTTP status code, 0 if no response was received
+	StatusCode int
+	// ErrorType is empty if the operation succeeded, otherwise the Marqo
+	// error code (e.g. "index_not_found"), or one of "canceled",
+	// "deadline_exceeded", "circuit_open" and "network"
+	ErrorType string
+	// DocumentsUpserted is the number of documents upserted successfully,
+	// 0 if the response is larger than 1 MiB
+	DocumentsUpserted int
+	// ProcessingTimeMS is the processing time reported by the server, nil
+	// if the response has none or is larger than 1 MiB
+	ProcessingTimeMS *float64
+}
+
+// WithMetrics sets the recorder which receives the metrics of every
+// operation.
+//
+// Example usage:
+//
+//	collector := marqoprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	client, err := marqo.NewClient("http://localhost:8882", marqo.WithMetrics(collector))
+func WithMetrics(recorder MetricsRecorder) func(*Client) {
+	return func(c *Client) {
+		c.metrics = recorder
+	}
+}
+
+// metricsRoundTrip is the round trip wrapper which records the metrics of
+// the operation of the request
+func (c *Client) metricsRoundTrip(next req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		op := operationFromContext(r.Context())
+		start := time.Now()
+		resp, err := next.RoundTrip(r)
+
+		metrics := OperationMetrics{
+			Operation: op.name,
+			IndexName: op.indexName,
+			Duration:  time.Since(start),
+			ErrorType: errorType(resp, err),
+		}
+		if err == nil && resp != nil && resp.Response != nil {
+			metrics.StatusCode = resp.Response.StatusCode
+			if summary := op.summarize(resp); summary != nil {
+				metrics.ProcessingTimeMS = summary.ProcessingTimeMS
+				if op.name == "UpsertDocuments" && metrics.ErrorType == "" {
+					for _, item := range summary.Items {
+						if item.Status >= http.StatusOK && item.Status < http.StatusMultipleChoices {
+							metrics.DocumentsUpserted++
+						}
+					}
+				}
+			}
+		}
+		c.metrics.RecordOperation(metrics)
+		return resp, err
+	}
+}
+
+// errorType returns the error type of a request for the metrics, empty if
+// the request succeeded
+func errorType(resp *req.Response, err error) string {
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	default:
+		return "network"
+	}
+	if resp == nil || resp.Response == nil || resp.Response.StatusCode < http.StatusBadRequest {
+		return ""
+	}
+	apiErr := newAPIError(resp)
+	if apiErr.Code != "" {
+		return apiErr.Code
+	}
+	if apiErr.Type != "" {
+		return apiErr.Type
+	}
+	return "http_" + strconv.Itoa(resp.Response.StatusCode)
+}
@@ -0,0 +1,119 @@
+package marqo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMetricsRecorder keeps the recorded metrics
+type fakeMetricsRecorder struct {
+	mu      sync.Mutex
+	metrics []OperationMetrics
+}
+
+func (r *fakeMetricsRecorder) RecordOperation(metrics OperationMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, metrics)
+}
+
+func TestMetrics(t *testing.T) {
+	closedServer := getMockServerForError(http.StatusOK, `{}`)
+	closedServer.Close()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name              string
+		statusCode        int
+		body              string
+		closed            bool
+		ctx               context.Context
+		wantErrorType     string
+		wantProcessing    bool
+		wantDocumentCount int
+	}{
+		{
+			name:              "upsert with a failed document",
+			statusCode:        http.StatusOK,
+			body:              `{"errors": true, "items": [{"_id": "1", "status": 201}, {"_id": "2", "status": 400}], "processingTimeMs": 7}`,
+			ctx:               context.Background(),
+			wantProcessing:    true,
+			wantDocumentCount: 1,
+		},
+		{
+			name:       "upsert too large to summarize",
+			statusCode: http.StatusOK,
+			body: `{"errors": false, "items": [{"_id": "` + strings.Repeat("1", maxSummaryBodySize) + `", "status": 200}],
+				"processingTimeMs": 7}`,
+			ctx: context.Background(),
+		},
+		{
+			name:          "marqo error",
+			statusCode:    http.StatusBadRequest,
+			body:          `{"code": "invalid_argument", "type": "invalid_request", "message": "bad request"}`,
+			ctx:           context.Background(),
+			wantErrorType: "invalid_argument",
+		},
+		{
+			name:          "error without code",
+			statusCode:    http.StatusInternalServerError,
+			body:          `internal error`,
+			ctx:           context.Background(),
+			wantErrorType: "http_500",
+		},
+		{
+			name:          "network error",
+			closed:        true,
+			ctx:           context.Background(),
+			wantErrorType: "network",
+		},
+		{
+			name:          "canceled",
+			statusCode:    http.StatusOK,
+			body:          `{}`,
+			ctx:           cancelled,
+			wantErrorType: "canceled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := closedServer.URL
+			if !tt.closed {
+				mockServer := getMockServerForError(tt.statusCode, tt.body)
+				defer mockServer.Close()
+				url = mockServer.URL
+			}
+			recorder := &fakeMetricsRecorder{}
+			c, err := NewClient(url, WithMetrics(recorder))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			// nolint
+			c.UpsertDocumentsWithContext(tt.ctx, &UpsertDocumentsRequest{
+				IndexName: "test",
+				Documents: []interface{}{map[string]interface{}{"_id": "1"}, map[string]interface{}{"_id": "2"}},
+			})
+
+			if len(recorder.metrics) != 1 {
+				t.Fatalf("recorded metrics = %v, want 1", len(recorder.metrics))
+			}
+			got := recorder.metrics[0]
+			if got.Operation != "UpsertDocuments" || got.IndexName != "test" {
+				t.Errorf("operation = %v %v, want UpsertDocuments test", got.Operation, got.IndexName)
+			}
+			if got.ErrorType != tt.wantErrorType {
+				t.Errorf("ErrorType = %v, want %v", got.ErrorType, tt.wantErrorType)
+			}
+			if (got.ProcessingTimeMS != nil) != tt.wantProcessing {
+				t.Errorf("ProcessingTimeMS = %v, want set %v", got.ProcessingTimeMS, tt.wantProcessing)
+			}
+			if got.DocumentsUpserted != tt.wantDocumentCount {
+				t.Errorf("DocumentsUpserted = %v, want %v", got.DocumentsUpserted, tt.wantDocumentCount)
+			}
+		})
+	}
+}
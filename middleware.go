@@ -0,0 +1,102 @@
+package marqo
+
+import (
+	"net/http"
+)
+
+// Operation describes the client method a request is sent for
+type Operation struct {
+	// Name is the name of the client method, e.g. "Search"
+	Name string
+	// IndexName is the index the request targets, empty if none
+	IndexName string
+	// Request is the request passed to the client method, e.g. a
+	// *SearchRequest, nil for methods without a request
+	Request interface{}
+	// Class is the rate limiting class of the method
+	Class OperationClass
+	// Idempotent is true if the request can be safely sent more than once
+	Idempotent bool
+}
+
+// Handler sends the HTTP request of an operation and returns the HTTP
+// response
+type Handler func(op Operation, r *http.Request) (*http.Response, error)
+
+// Middleware wraps the Handler which sends the requests, e.g. to add
+// headers, audit the operations or inspect the payloads
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to the client. The middleware runs for
+// every request sent by the client methods, including each retry and
+// failover attempt, and the first middleware added is the outermost one.
+// As with an http.RoundTripper, a middleware which changes the request
+// must clone it first.
+//
+// Example usage:
+//
+//	audit := func(next marqo.Handler) marqo.Handler {
+//	    return func(op marqo.Operation, r *http.Request) (*http.Response, error) {
+//	        resp, err := next(op, r)
+//	        log.Printf("%s on index %q: %v", op.Name, op.IndexName, err)
+//	        return resp, err
+//	    }
+//	}
+//	client, err := marqo.NewClient("http://localhost:8882", marqo.WithMiddleware(audit))
+func WithMiddleware(middleware ...Middleware) func(*Client) {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// export returns the public descriptor of the operation
+func (op *operation) export() Operation {
+	return Operation{
+		Name:       op.name,
+		IndexName:  op.indexName,
+		Request:    op.request,
+		Class:      op.class,
+		Idempotent: op.idempotent,
+	}
+}
+
+// middlewareTransport is the http.RoundTripper which runs the middleware
+// before the wrapped transport
+type middlewareTransport struct {
+	next    http.RoundTripper
+	handler Handler
+}
+
+// newMiddlewareTransport chains the middleware in front of next
+func newMiddlewareTransport(next http.RoundTripper, middleware []Middleware) *middlewareTransport {
+	handler := Handler(func(_ Operation, r *http.Request) (*http.Response, error) {
+		return next.RoundTrip(r)
+	})
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return &middlewareTransport{next: next, handler: handler}
+}
+
+// RoundTrip runs the middleware for requests of client methods. Other
+// requests, such as the endpoint health probes, skip the middleware.
+func (t *middlewareTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	op, ok := r.Context().Value(operationKey{}).(*operation)
+	if !ok {
+		return t.next.RoundTrip(r)
+	}
+	return t.handler(op.export(), r)
+}
+
+// setMiddleware wraps the transport of reqClient with the middleware
+func (c *Client) setMiddleware() {
+	if len(c.middleware) == 0 {
+		return
+	}
+	httpClient := c.reqClient.GetClient()
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	httpClient.Transport = newMiddlewareTransport(transport, c.middleware)
+}
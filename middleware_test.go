@@ -0,0 +1,137 @@
+package marqo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// getMockServerForMiddleware returns a server which echoes the x-tenant
+// header in the response
+func getMockServerForMiddleware() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-tenant", r.Header.Get("x-tenant"))
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"hits": [], "results": []}`))
+		}))
+}
+
+func TestMiddleware(t *testing.T) {
+	mockServer := getMockServerForMiddleware()
+	defer mockServer.Close()
+
+	var (
+		mu     sync.Mutex
+		calls  []string
+		ops    []Operation
+		bodies []string
+		tenant string
+	)
+	named := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(op Operation, r *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name)
+				mu.Unlock()
+				return next(op, r)
+			}
+		}
+	}
+	addHeader := func(next Handler) Handler {
+		return func(op Operation, r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			r.Header.Set("x-tenant", "acme")
+			return next(op, r)
+		}
+	}
+	audit := func(next Handler) Handler {
+		return func(op Operation, r *http.Request) (*http.Response, error) {
+			var body []byte
+			if r.GetBody != nil {
+				reader, err := r.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				body, _ = io.ReadAll(reader)
+			}
+			resp, err := next(op, r)
+			mu.Lock()
+			defer mu.Unlock()
+			ops = append(ops, op)
+			bodies = append(bodies, string(body))
+			if resp != nil {
+				tenant = resp.Header.Get("x-tenant")
+			}
+			return resp, err
+		}
+	}
+
+	c, err := NewClient(mockServer.URL, WithMiddleware(named("first"), named("second")), WithMiddleware(audit, addHeader))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	query := "shoes"
+	searchReq := &SearchRequest{IndexName: "products", Q: &query}
+	if _, err := c.Search(searchReq); err != nil {
+		t.Fatalf("Client.Search() error = %v", err)
+	}
+	if _, err := c.ListIndexes(); err != nil {
+		t.Fatalf("Client.ListIndexes() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(calls, ",") != "first,second,first,second" {
+		t.Errorf("middleware calls = %v, want first,second,first,second", calls)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("operations = %v, want 2", len(ops))
+	}
+	if ops[0].Name != "Search" || ops[0].IndexName != "products" || ops[0].Request != searchReq ||
+		ops[0].Class != OperationClassSearch || !ops[0].Idempotent {
+		t.Errorf("first operation = %+v, want Search on products", ops[0])
+	}
+	if ops[1].Name != "ListIndexes" || ops[1].IndexName != "" || ops[1].Request != nil {
+		t.Errorf("second operation = %+v, want ListIndexes", ops[1])
+	}
+	if !bytes.Contains([]byte(bodies[0]), []byte(`"q":"shoes"`)) {
+		t.Errorf("search body = %v, want the query", bodies[0])
+	}
+	if tenant != "acme" {
+		t.Errorf("x-tenant header = %v, want acme", tenant)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	mockServer := getMockServerForMiddleware()
+	defer mockServer.Close()
+
+	errDenied := io.ErrClosedPipe
+	deny := func(next Handler) Handler {
+		return func(op Operation, r *http.Request) (*http.Response, error) {
+			if op.Class == OperationClassAdmin {
+				return nil, errDenied
+			}
+			return next(op, r)
+		}
+	}
+	c, err := NewClient(mockServer.URL, WithMiddleware(deny))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := c.ListIndexes(); !errors.Is(err, errDenied) {
+		t.Errorf("Client.ListIndexes() error = %v, want %v", err, errDenied)
+	}
+	query := "shoes"
+	if _, err := c.Search(&SearchRequest{IndexName: "products", Q: &query}); err != nil {
+		t.Errorf("Client.Search() error = %v, want nil", err)
+	}
+}
@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -38,11 +39,17 @@ type Model struct {
 //	}
 //	fmt.Printf("Loaded models: %+v\n", modelsResponse.Models)
 func (c *Client) GetModels() (*GetModelsResponse, error) {
-	logger := c.logger.With("method", "GetModels")
+	return c.GetModelsWithContext(context.Background())
+}
+
+// GetModelsWithContext is like GetModels but sends the request with ctx.
+func (c *Client) GetModelsWithContext(ctx context.Context) (_ *GetModelsResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetModels")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetModels")
 	var result GetModelsResponse
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetModels", "", nil).
 		SetSuccessResult(&result).
 		Get(c.reqClient.BaseURL + "/models")
 	if err != nil {
@@ -50,11 +57,13 @@ func (c *Client) GetModels() (*GetModelsResponse, error) {
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error getting models", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting models: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting models", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting models: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response models: %+v", result))
+	c.logResponse(ctx, logger, resp, "got models",
+		"models", len(result.Models))
 	return &result, nil
 }
 
@@ -95,16 +104,22 @@ type EjectModelRequest struct {
 //	    log.Fatalf("Failed to eject model: %v", err)
 //	}
 func (c *Client) EjectModel(ejectModelReq *EjectModelRequest) error {
-	logger := c.logger.With("method", "EjectModel")
-	err := validate.Struct(ejectModelReq)
+	return c.EjectModelWithContext(context.Background(), ejectModelReq)
+}
+
+// EjectModelWithContext is like EjectModel but sends the request with ctx.
+func (c *Client) EjectModelWithContext(ctx context.Context, ejectModelReq *EjectModelRequest) (err error) {
+	ctx, span := c.startSpan(ctx, "EjectModel")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("EjectModel")
+	err = validate.Struct(ejectModelReq)
 	if err != nil {
 		logger.Error("error validating eject model request",
 			"error", err)
 		return err
 	}
 
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "EjectModel", "", ejectModelReq).
 		SetQueryParams(
 			map[string]string{
 				"model_name":   ejectModelReq.ModelName,
@@ -117,13 +132,12 @@ func (c *Client) EjectModel(ejectModelReq *EjectModelRequest) error {
 		return err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error ejecting model", "status_code", resp.
-			Response.StatusCode)
-		return fmt.Errorf("error ejecting model: status code: %v",
-			resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error ejecting model", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return fmt.Errorf("error ejecting model: %w", apiErr)
 	}
 
-	logger.Info("ejected model successfully",
+	c.logResponse(ctx, logger, resp, "ejected model successfully",
 		"model_name", ejectModelReq.ModelName,
 		"model_device", ejectModelReq.ModelDevice)
 	return nil
@@ -0,0 +1,151 @@
+package marqo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OperationClass groups client methods for client side rate limiting
+type OperationClass string
+
+const (
+	// OperationClassSearch is for Search, BulkSearch, GetDocument and GetDocuments
+	OperationClassSearch OperationClass = "search"
+	// OperationClassWrite is for UpsertDocuments, DeleteDocuments and RefreshIndex
+	OperationClassWrite OperationClass = "write"
+	// OperationClassAdmin is for index, model and device management methods
+	OperationClassAdmin OperationClass = "admin"
+)
+
+// operation describes the client method a request is sent for
+type operation struct {
+	// name is the name of the client method, e.g. "Search"
+	name string
+	// indexName is the index the request targets, empty if none
+	indexName string
+	// idempotent is true if the request can be safely sent more than once
+	idempotent bool
+	// class is the rate limiting class of the method
+	class OperationClass
+	// request is the request passed to the client method, nil if none
+	request interface{}
+	// start is when the client method was called
+	start time.Time
+	// span is the span of the client method, nil if tracing is disabled
+	span trace.Span
+	// summary is the summary of the body of the response summarized, which
+	// the round trip wrappers share
+	summarized *req.Response
+	summary    *responseSummary
+}
+
+// maxSummaryBodySize is the size of the largest response body summarized
+const maxSummaryBodySize = 1 << 20
+
+// responseSummary holds the fields of a response body read by the round
+// trip wrappers
+type responseSummary struct {
+	ProcessingTimeMS *float64 `json:"processingTimeMs"`
+	// Items are the statuses of the documents of an upsert
+	Items []struct {
+		Status int `json:"status"`
+	} `json:"items"`
+}
+
+// summarize returns the summary of the response body, decoded once per
+// response. It is nil if the body is not a JSON object or is larger than
+// maxSummaryBodySize.
+func (op *operation) summarize(resp *req.Response) *responseSummary {
+	if op.summarized == resp {
+		return op.summary
+	}
+	op.summarized = resp
+	op.summary = nil
+	if resp.Response.ContentLength > maxSummaryBodySize {
+		return nil
+	}
+	body := resp.Bytes()
+	if len(body) > maxSummaryBodySize {
+		return nil
+	}
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) == 0 || body[0] != '{' {
+		return nil
+	}
+	var summary responseSummary
+	if json.Unmarshal(body, &summary) != nil {
+		return nil
+	}
+	op.summary = &summary
+	return op.summary
+}
+
+// operationSpec holds the static properties of a client method
+type operationSpec struct {
+	idempotent bool
+	class      OperationClass
+}
+
+// operationSpecs lists the properties of the client methods. CreateIndex,
+// DeleteIndex and UpsertDocuments are not idempotent as repeating them
+// either fails or may create duplicate documents.
+var operationSpecs = map[string]operationSpec{
+	"Search":           {idempotent: true, class: OperationClassSearch},
+	"BulkSearch":       {idempotent: true, class: OperationClassSearch},
+	"GetDocument":      {idempotent: true, class: OperationClassSearch},
+	"GetDocuments":     {idempotent: true, class: OperationClassSearch},
+	"UpsertDocuments":  {idempotent: false, class: OperationClassWrite},
+	"DeleteDocuments":  {idempotent: true, class: OperationClassWrite},
+	"RefreshIndex":     {idempotent: true, class: OperationClassWrite},
+	"CreateIndex":      {idempotent: false, class: OperationClassAdmin},
+	"DeleteIndex":      {idempotent: false, class: OperationClassAdmin},
+	"ListIndexes":      {idempotent: true, class: OperationClassAdmin},
+	"GetIndexHealth":   {idempotent: true, class: OperationClassAdmin},
+	"GetIndexSettings": {idempotent: true, class: OperationClassAdmin},
+	"GetIndexStats":    {idempotent: true, class: OperationClassAdmin},
+	"GetModels":        {idempotent: true, class: OperationClassAdmin},
+	"EjectModel":       {idempotent: true, class: OperationClassAdmin},
+	"GetCPUInfo":       {idempotent: true, class: OperationClassAdmin},
+	"GetCUDAInfo":      {idempotent: true, class: OperationClassAdmin},
+	"GetVersion":       {idempotent: true, class: OperationClassAdmin},
+}
+
+// operationKey is the context key for the operation of a request
+type operationKey struct{}
+
+// newRequest creates a request for the named client method. The operation
+// is stored in the request context so that the round trip wrappers can
+// apply per operation rules. The attributes of the operation are added to
+// the span of the method, started by startSpan.
+func (c *Client) newRequest(ctx context.Context, name string, indexName string, request interface{}) *req.Request {
+	spec := operationSpecs[name]
+	op := &operation{
+		name:       name,
+		indexName:  indexName,
+		idempotent: spec.idempotent,
+		class:      spec.class,
+		request:    request,
+		start:      time.Now(),
+	}
+	if c.tracer != nil {
+		op.span = trace.SpanFromContext(ctx)
+		op.span.SetAttributes(operationAttributes(op)...)
+	}
+	return c.reqClient.
+		R().
+		SetContext(context.WithValue(ctx, operationKey{}, op))
+}
+
+// operationFromContext returns the operation stored in ctx
+func operationFromContext(ctx context.Context) *operation {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return &operation{}
+	}
+	return op
+}
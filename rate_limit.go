@@ -0,0 +1,232 @@
+package marqo
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/imroc/req/v3"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is the client side limit for an operation class
+type RateLimit struct {
+	// RequestsPerSecond is the rate of the token bucket, 0 disables the rate limit
+	RequestsPerSecond float64
+	// Burst is the size of the token bucket (default: RequestsPerSecond rounded up)
+	Burst int
+	// MaxInFlight is the maximum number of concurrent requests, 0 is unlimited
+	MaxInFlight int
+}
+
+// classPriority orders the classes when they compete for the shared
+// in-flight limit, lower runs first
+var classPriority = map[OperationClass]int{
+	OperationClassSearch: 0,
+	OperationClassAdmin:  1,
+	OperationClassWrite:  2,
+}
+
+// WithRateLimit sets the client side rate limit for an operation class.
+// Requests wait for a token and a free in-flight slot before being sent;
+// waiting is aborted when the request context is done.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithRateLimit(marqo.OperationClassWrite, marqo.RateLimit{
+//	        RequestsPerSecond: 10,
+//	        MaxInFlight:       2,
+//	    }),
+//	)
+func WithRateLimit(class OperationClass, limit RateLimit) func(*Client) {
+	return func(c *Client) {
+		c.limiter.setRateLimit(class, limit)
+	}
+}
+
+// WithMaxInFlight limits the number of concurrent requests across all
+// operation classes. When a slot frees up, waiting searches get it before
+// admin requests, and admin requests before writes, so that ingestion does
+// not starve user facing searches.
+func WithMaxInFlight(maxInFlight int) func(*Client) {
+	return func(c *Client) {
+		c.limiter.setMaxInFlight(maxInFlight)
+	}
+}
+
+// SetRateLimit changes the rate limit of an operation class at runtime
+func (c *Client) SetRateLimit(class OperationClass, limit RateLimit) {
+	c.limiter.setRateLimit(class, limit)
+}
+
+// SetMaxInFlight changes the limit of concurrent requests across all
+// operation classes at runtime, 0 is unlimited
+func (c *Client) SetMaxInFlight(maxInFlight int) {
+	c.limiter.setMaxInFlight(maxInFlight)
+}
+
+// limiter applies the rate and in-flight limits to the requests
+type limiter struct {
+	mu      sync.Mutex
+	classes map[OperationClass]*classLimiter
+	// maxInFlight and inFlight are the limit and count across all classes
+	maxInFlight int
+	inFlight    int
+	// changed is closed and replaced whenever a slot frees up or a limit
+	// changes, to wake up the waiting requests
+	changed chan struct{}
+}
+
+// classLimiter holds the limits and counters of one operation class
+type classLimiter struct {
+	limit    RateLimit
+	bucket   *rate.Limiter
+	inFlight int
+	// waiting is the number of requests waiting for an in-flight slot
+	waiting int
+}
+
+// newLimiter creates a limiter without any limits
+func newLimiter() *limiter {
+	return &limiter{
+		classes: map[OperationClass]*classLimiter{},
+		changed: make(chan struct{}),
+	}
+}
+
+// setRateLimit sets the limit of a class
+func (l *limiter) setRateLimit(class OperationClass, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cl := l.class(class)
+	cl.limit = limit
+	if limit.RequestsPerSecond <= 0 {
+		cl.bucket.SetLimit(rate.Inf)
+		l.broadcast()
+		return
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(limit.RequestsPerSecond))
+	}
+	cl.bucket.SetBurst(burst)
+	cl.bucket.SetLimit(rate.Limit(limit.RequestsPerSecond))
+	l.broadcast()
+}
+
+// setMaxInFlight sets the limit across all classes
+func (l *limiter) setMaxInFlight(maxInFlight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxInFlight = maxInFlight
+	l.broadcast()
+}
+
+// class returns the limiter of the class, creating an unlimited one if
+// needed. It must be called with mu held.
+func (l *limiter) class(class OperationClass) *classLimiter {
+	cl, ok := l.classes[class]
+	if !ok {
+		cl = &classLimiter{
+			bucket: rate.NewLimiter(rate.Inf, 0),
+		}
+		l.classes[class] = cl
+	}
+	return cl
+}
+
+// broadcast wakes up the waiting requests. It must be called with mu held.
+func (l *limiter) broadcast() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// roundTrip is the round trip wrapper which waits for the limits of the
+// operation class before sending the request
+func (l *limiter) roundTrip(next req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		release, err := l.acquire(r.Context(), operationFromContext(r.Context()).class)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return next.RoundTrip(r)
+	}
+}
+
+// acquire waits for a token and an in-flight slot of the class and returns
+// the function to release the slot
+func (l *limiter) acquire(ctx context.Context, class OperationClass) (func(), error) {
+	l.mu.Lock()
+	cl := l.class(class)
+	bucket := cl.bucket
+	l.mu.Unlock()
+
+	if err := bucket.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	waiting := false
+	for !l.canRun(class, cl) {
+		if !waiting {
+			waiting = true
+			cl.waiting++
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			cl.waiting--
+			// a lower priority request may be able to run now
+			l.broadcast()
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		case <-changed:
+		}
+		l.mu.Lock()
+	}
+	if waiting {
+		cl.waiting--
+	}
+	cl.inFlight++
+	l.inFlight++
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		cl.inFlight--
+		l.inFlight--
+		l.broadcast()
+		l.mu.Unlock()
+	}, nil
+}
+
+// canRun reports whether a request of the class can take an in-flight
+// slot. It must be called with mu held.
+func (l *limiter) canRun(class OperationClass, cl *classLimiter) bool {
+	if cl.limit.MaxInFlight > 0 && cl.inFlight >= cl.limit.MaxInFlight {
+		return false
+	}
+	if l.maxInFlight <= 0 {
+		return true
+	}
+	if l.inFlight >= l.maxInFlight {
+		return false
+	}
+	// leave the shared slot to a waiting class of higher priority which is
+	// only held back by the shared limit
+	for other, ol := range l.classes {
+		if other == class || ol.waiting == 0 || classPriority[other] >= classPriority[class] {
+			continue
+		}
+		if ol.limit.MaxInFlight <= 0 || ol.inFlight < ol.limit.MaxInFlight {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package marqo
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+	Logger *slog.Logger
+}
+
+func (suite *RateLimitTestSuite) SetupSuite() {
+	suite.Logger = slog.New(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{
+			AddSource: true,
+			Level:     slog.LevelError,
+		}))
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
+
+// getMockServerForRateLimit returns a server which records the order of
+// the requests and blocks them until release is closed
+func getMockServerForRateLimit(release chan struct{}, mu *sync.Mutex, paths *[]string, maxConcurrent *int) *httptest.Server {
+	var concurrent int
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			*paths = append(*paths, r.URL.Path)
+			concurrent++
+			if concurrent > *maxConcurrent {
+				*maxConcurrent = concurrent
+			}
+			mu.Unlock()
+
+			<-release
+
+			mu.Lock()
+			concurrent--
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{}`))
+		}))
+}
+
+func (suite *RateLimitTestSuite) TestMaxInFlightPerClass() {
+	t := suite.T()
+	var (
+		mu            sync.Mutex
+		paths         []string
+		maxConcurrent int
+	)
+	release := make(chan struct{})
+	mockServer := getMockServerForRateLimit(release, &mu, &paths, &maxConcurrent)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL,
+		WithLogger(suite.Logger),
+		WithRateLimit(OperationClassWrite, RateLimit{MaxInFlight: 1}),
+	)
+	suite.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// nolint
+			c.UpsertDocuments(&UpsertDocumentsRequest{
+				IndexName: "test",
+				Documents: []interface{}{map[string]interface{}{"_id": "1"}},
+			})
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxConcurrent != 1 {
+		t.Errorf("max concurrent writes = %v, want 1", maxConcurrent)
+	}
+	if len(paths) != 3 {
+		t.Errorf("requests = %v, want 3", len(paths))
+	}
+}
+
+func (suite *RateLimitTestSuite) TestSearchPriority() {
+	t := suite.T()
+	var (
+		mu            sync.Mutex
+		paths         []string
+		maxConcurrent int
+	)
+	release := make(chan struct{})
+	mockServer := getMockServerForRateLimit(release, &mu, &paths, &maxConcurrent)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL,
+		WithLogger(suite.Logger),
+		WithMaxInFlight(1),
+	)
+	suite.Require().NoError(err)
+
+	upsert := func() {
+		// nolint
+		c.UpsertDocuments(&UpsertDocumentsRequest{
+			IndexName: "test",
+			Documents: []interface{}{map[string]interface{}{"_id": "1"}},
+		})
+	}
+	query := "query"
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	// the first write takes the only slot
+	go func() { defer wg.Done(); upsert() }()
+	time.Sleep(30 * time.Millisecond)
+	// a second write queues before the search
+	go func() { defer wg.Done(); upsert() }()
+	time.Sleep(30 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		// nolint
+		c.Search(&SearchRequest{IndexName: "test", Q: &query})
+	}()
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) != 3 {
+		t.Fatalf("requests = %v, want 3", paths)
+	}
+	if !strings.HasSuffix(paths[1], "/search") {
+		t.Errorf("second request = %v, want the search", paths[1])
+	}
+}
+
+func (suite *RateLimitTestSuite) TestSetRateLimitAtRuntime() {
+	t := suite.T()
+	mockServer := getMockServerForError(http.StatusOK, `{"hits": []}`)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL, WithLogger(suite.Logger))
+	suite.Require().NoError(err)
+	query := "query"
+	search := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.SearchWithContext(ctx, &SearchRequest{IndexName: "test", Q: &query})
+		return err
+	}
+
+	c.SetRateLimit(OperationClassSearch, RateLimit{RequestsPerSecond: 0.01, Burst: 1})
+	if err := search(); err != nil {
+		t.Fatalf("first search error = %v, want nil", err)
+	}
+	if err := search(); err == nil {
+		t.Fatalf("second search error = nil, want rate limit error")
+	}
+
+	c.SetRateLimit(OperationClassSearch, RateLimit{})
+	if err := search(); err != nil {
+		t.Errorf("search after removing the limit error = %v, want nil", err)
+	}
+}
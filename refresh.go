@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -49,27 +50,42 @@ type RefreshIndexResponse struct {
 //	}
 //	fmt.Printf("RefreshIndexResponse: %+v\n", resp)
 func (c *Client) RefreshIndex(refreshIndexReq *RefreshIndexRequest) (*RefreshIndexResponse, error) {
-	logger := c.logger.With("method", "RefreshIndex")
-	err := validate.Struct(refreshIndexReq)
+	return c.RefreshIndexWithContext(context.Background(), refreshIndexReq)
+}
+
+// RefreshIndexWithContext is like RefreshIndex but sends the request with ctx.
+func (c *Client) RefreshIndexWithContext(ctx context.Context, refreshIndexReq *RefreshIndexRequest) (_ *RefreshIndexResponse, err error) {
+	ctx, span := c.startSpan(ctx, "RefreshIndex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("RefreshIndex")
+	c.setDefaultIndex(&refreshIndexReq.IndexName)
+	err = validate.Struct(refreshIndexReq)
 	if err != nil {
 		logger.Error("error validating refresh index request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, refreshIndexReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var refreshIndexResp RefreshIndexResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "RefreshIndex", indexName, refreshIndexReq).
 		SetSuccessResult(&refreshIndexResp).
-		Post(c.reqClient.BaseURL + "/indexes/" + refreshIndexReq.IndexName + "/_refresh")
+		Post(c.reqClient.BaseURL + "/indexes/" + indexName + "/_refresh")
 	if err != nil {
 		logger.Error("error refreshing index", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error refreshing index", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error refreshing index: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error refreshing index", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error refreshing index: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response refresh index: %+v", refreshIndexResp))
+	c.logResponse(ctx, logger, resp, "index refreshed",
+		"index", indexName,
+		"failed_shards", refreshIndexResp.Shards.Failed)
 	return &refreshIndexResp, nil
 }
@@ -0,0 +1,403 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxListPasses is the number of times the documents of an index are
+// searched at most to list them
+const maxListPasses = 3
+
+// ReindexRequest is the request for rebuilding the index behind an alias
+type ReindexRequest struct {
+	// Alias is the alias to point to the new index. If it is not an alias
+	// yet, the index of the same name, if any, is the one copied.
+	Alias string `validate:"required"`
+	// NewIndex is the request creating the new index
+	NewIndex *CreateIndexRequest `validate:"required"`
+	// TensorFields are the tensor fields of the copied documents, required
+	// by unstructured Marqo 2.x indexes
+	TensorFields []string
+	// DocumentIDs are the documents to copy, every document of the old
+	// index if empty
+	DocumentIDs []string
+	// BatchSize is the number of documents copied per request (default: 100)
+	BatchSize int `validate:"gte=0"`
+	// DeleteOld deletes the old index after the alias is swapped
+	DeleteOld bool
+}
+
+// ReindexResponse is the response for rebuilding the index behind an alias
+type ReindexResponse struct {
+	// OldIndex is the index the alias pointed to, empty if there was none
+	OldIndex string
+	// NewIndex is the index the alias points to
+	NewIndex string
+	// DocumentsCopied is the number of documents copied to the new index
+	DocumentsCopied int
+}
+
+// Reindex rebuilds the index behind an alias with new settings.
+//
+// This method copies the documents of the index the alias points to into a
+// new index, and points the alias to it once both indexes hold the same
+// number of documents. Searches through the alias keep hitting the old index
+// until the swap. The documents are vectorised again by the new index. If
+// the copy fails, the new index is deleted. Without DocumentIDs, every
+// document of the old index is listed like ExportIndex lists them.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context of the requests.
+//	reindexReq (*ReindexRequest): The request containing the alias and the new index.
+//
+// Returns:
+//
+//	*ReindexResponse: The old and new indexes and the number of copied documents.
+//	error: An error if the operation fails, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Validates the request and resolves the index the alias points to.
+// 2. Creates the new index and waits for it to be ready.
+// 3. Lists the ids of every document of the old index if DocumentIDs is empty, and copies
+// the documents in batches with GetDocuments.
+// 4. Compares the number of documents of both indexes with GetIndexStats, or of the
+// new index with the number of copied documents if DocumentIDs is set, and fails
+// without swapping the alias if they differ or documents of DocumentIDs were not found.
+// 5. Swaps the alias to the new index, failing with ErrAliasConflict if it was moved meanwhile.
+// 6. Deletes the new index if any of the steps 2 to 5 failed, or the old index if
+// DeleteOld is set.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithAliasStore(marqo.NewFileAliasStore("aliases.json")),
+//	)
+//	reindexResp, err := client.Reindex(ctx, &marqo.ReindexRequest{
+//	    Alias: "products",
+//	    NewIndex: &marqo.CreateIndexRequest{
+//	        IndexName:     "products-v2",
+//	        IndexDefaults: &marqo.IndexDefaults{Model: &model},
+//	    },
+//	    DeleteOld: true,
+//	})
+//	if err != nil {
+//	    log.Fatalf("Failed to reindex: %v", err)
+//	}
+//	fmt.Printf("Copied %d documents to %s\n", reindexResp.DocumentsCopied, reindexResp.NewIndex)
+func (c *Client) Reindex(ctx context.Context, reindexReq *ReindexRequest) (_ *ReindexResponse, err error) {
+	ctx, span := c.startSpan(ctx, "Reindex")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("Reindex")
+	err = validate.Struct(reindexReq)
+	if err != nil {
+		logger.Error("error validating reindex request", "error", err)
+		return nil, err
+	}
+	if c.aliases == nil {
+		return nil, fmt.Errorf("%w: client has no alias store", ErrInvalidArgument)
+	}
+	newIndex := reindexReq.NewIndex.IndexName
+	if _, isAlias, err := c.aliases.Resolve(ctx, newIndex); err != nil || isAlias {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: new index %s is an alias", ErrInvalidArgument, newIndex)
+	}
+
+	// the alias points to an index, or shadows the index of the same name
+	oldIndex, isAlias, err := c.aliases.Resolve(ctx, reindexReq.Alias)
+	if err != nil {
+		return nil, err
+	}
+	swapFrom := oldIndex
+	if !isAlias {
+		listIndexesResp, err := c.ListIndexesWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range listIndexesResp.Results {
+			if result.IndexName == reindexReq.Alias {
+				oldIndex = reindexReq.Alias
+				break
+			}
+		}
+	}
+	if oldIndex == newIndex {
+		return nil, fmt.Errorf("%w: alias %s already points to %s", ErrInvalidArgument, reindexReq.Alias, newIndex)
+	}
+	logger = logger.With("alias", reindexReq.Alias, "old_index", oldIndex, "new_index", newIndex)
+
+	createIndexReq := *reindexReq.NewIndex
+	createIndexReq.Wait = true
+	createIndexResp, err := c.CreateIndexWithContext(ctx, &createIndexReq)
+	if err != nil {
+		logger.Error("error creating new index", "error", err)
+		if createIndexResp != nil {
+			// created but not ready
+			return nil, c.removeNewIndex(ctx, newIndex, err)
+		}
+		return nil, err
+	}
+
+	reindexResp := &ReindexResponse{OldIndex: oldIndex, NewIndex: newIndex}
+	if oldIndex != "" {
+		reindexResp.DocumentsCopied, err = c.copyDocuments(ctx, reindexReq, oldIndex)
+		if err != nil {
+			logger.Error("error copying documents", "copied", reindexResp.DocumentsCopied, "error", err)
+			return reindexResp, c.removeNewIndex(ctx, newIndex, err)
+		}
+		if err := c.compareDocumentCounts(ctx, reindexReq, oldIndex, reindexResp.DocumentsCopied); err != nil {
+			logger.Error("error validating new index", "error", err)
+			return reindexResp, c.removeNewIndex(ctx, newIndex, err)
+		}
+	}
+
+	err = c.aliases.Swap(ctx, reindexReq.Alias, swapFrom, newIndex)
+	if err != nil {
+		logger.Error("error swapping alias", "error", err)
+		return reindexResp, c.removeNewIndex(ctx, newIndex, err)
+	}
+	logger.Info("alias swapped", "copied", reindexResp.DocumentsCopied)
+
+	if reindexReq.DeleteOld && oldIndex != "" {
+		// the alias may shadow the old index, which DeleteIndex rejects
+		_, err = c.deleteIndex(ctx, &DeleteIndexRequest{IndexName: oldIndex}, false)
+		if err != nil {
+			logger.Error("error deleting old index", "error", err)
+			return reindexResp, err
+		}
+	}
+	return reindexResp, nil
+}
+
+// removeNewIndex deletes the new index of a failed reindex, even if ctx is
+// done, and returns err joined with the error of the deletion, if any
+func (c *Client) removeNewIndex(ctx context.Context, newIndex string, err error) error {
+	_, deleteErr := c.deleteIndex(context.WithoutCancel(ctx), &DeleteIndexRequest{IndexName: newIndex}, false)
+	if deleteErr != nil {
+		c.methodLogger("Reindex").Error("error deleting new index", "new_index", newIndex, "error", deleteErr)
+		return errors.Join(err, fmt.Errorf("error deleting new index %s, it is left behind: %w", newIndex, deleteErr))
+	}
+	return err
+}
+
+// copyDocuments copies the documents of oldIndex to the new index and
+// returns the number of documents copied. The documents of DocumentIDs must
+// all be found, the ones deleted since they were listed are caught by the
+// document counts.
+func (c *Client) copyDocuments(ctx context.Context, reindexReq *ReindexRequest, oldIndex string) (int, error) {
+	copied := 0
+	missing, err := c.scanDocuments(ctx, oldIndex, reindexReq.DocumentIDs, reindexReq.BatchSize, false,
+		func(documents []map[string]interface{}) error {
+			upsertDocumentsReq := &UpsertDocumentsRequest{
+				IndexName:    reindexReq.NewIndex.IndexName,
+				Documents:    make([]interface{}, len(documents)),
+				TensorFields: reindexReq.TensorFields,
+			}
+			for i, document := range documents {
+				upsertDocumentsReq.Documents[i] = stripDocumentMetadata(document)
+			}
+			dialect, err := c.DetectDialect(ctx)
+			if err != nil {
+				return err
+			}
+			if dialect == DialectV1 {
+				// the counts are compared once copied
+				refresh := true
+				upsertDocumentsReq.Refresh = &refresh
+			}
+			if err := c.upsertBatch(ctx, upsertDocumentsReq); err != nil {
+				return err
+			}
+			copied += len(documents)
+			return nil
+		})
+	if err == nil && missing > 0 && len(reindexReq.DocumentIDs) > 0 {
+		err = fmt.Errorf("error copying documents: %d document ids not found in %s", missing, oldIndex)
+	}
+	return copied, err
+}
+
+// scanDocuments calls fn with the documents of the physical index in
+// batches of batchSize (default: 100) fetched with GetDocuments, and returns
+// the number of documents which were not found and skipped. The documents
+// are the ones of documentIDs, or every document of the index listed with
+// listDocumentIDs if it is empty. With exposeFacets, the documents include
+// their _tensor_facets.
+func (c *Client) scanDocuments(ctx context.Context, indexName string, documentIDs []string, batchSize int,
+	exposeFacets bool, fn func(documents []map[string]interface{}) error) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	// an alias may shadow the index
+	ctx = withPhysicalIndex(ctx)
+	missing := 0
+	fetch := func(ids []string) error {
+		getDocumentsResp, err := c.GetDocumentsWithContext(ctx, &GetDocumentsRequest{
+			IndexName:    indexName,
+			DocumentIDs:  ids,
+			ExposeFacets: exposeFacets,
+		})
+		if err != nil {
+			return err
+		}
+		var documents []map[string]interface{}
+		for _, document := range getDocumentsResp.Results {
+			if found, ok := document["_found"].(bool); ok && !found {
+				// deleted since it was listed, or a wrong id
+				missing++
+				continue
+			}
+			documents = append(documents, document)
+		}
+		if len(documents) == 0 {
+			return nil
+		}
+		return fn(documents)
+	}
+
+	if len(documentIDs) == 0 {
+		err := c.listDocumentIDs(ctx, indexName, batchSize, fetch)
+		return missing, err
+	}
+	for offset := 0; offset < len(documentIDs); offset += batchSize {
+		if err := fetch(documentIDs[offset:min(offset+batchSize, len(documentIDs))]); err != nil {
+			return missing, err
+		}
+	}
+	return missing, nil
+}
+
+// listDocumentIDs calls fn with the ids of every document of the index, in
+// pages of batchSize, once each. Marqo has no cursor over the documents, so
+// they are paged through with a lexical search matching every document and
+// retrieving only their _id. Writes during the search may shift the pages,
+// skipping documents, so the search is repeated until it found as many
+// documents as GetIndexStats reports, at most maxListPasses times. It returns
+// an error if documents are still missing, e.g. when the index has more
+// documents than the server pages through (10000 by default).
+func (c *Client) listDocumentIDs(ctx context.Context, indexName string, batchSize int, fn func(ids []string) error) error {
+	stats, err := c.GetIndexStatsWithContext(ctx, &GetIndexStatsRequest{IndexName: indexName})
+	if err != nil {
+		return err
+	}
+
+	q, searchMethod := "*", "LEXICAL"
+	seen := map[string]bool{}
+	for pass := 0; pass < maxListPasses && len(seen) < stats.NumberOfDocuments; pass++ {
+		found := len(seen)
+		for offset := 0; ; offset += batchSize {
+			limit, pageOffset := batchSize, offset
+			searchResp, err := c.SearchWithContext(ctx, &SearchRequest{
+				IndexName:            indexName,
+				Q:                    &q,
+				SearchMethod:         &searchMethod,
+				Limit:                &limit,
+				Offset:               &pageOffset,
+				AttributesToRetrieve: []string{"_id"},
+			})
+			if offset > 0 && errors.Is(err, ErrInvalidArgument) {
+				// past the hits the server pages through
+				break
+			}
+			if err != nil {
+				return err
+			}
+			var ids []string
+			for _, hit := range searchResp.Hits {
+				if id, ok := hit["_id"].(string); ok && !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+			if len(ids) > 0 {
+				if err := fn(ids); err != nil {
+					return err
+				}
+			}
+			if len(searchResp.Hits) < batchSize {
+				break
+			}
+		}
+		if len(seen) == found {
+			// nothing new, the next pass would not find more
+			break
+		}
+	}
+	if len(seen) < stats.NumberOfDocuments {
+		return fmt.Errorf("error listing documents of %s: found %d of %d documents, set the document ids",
+			indexName, len(seen), stats.NumberOfDocuments)
+	}
+	return nil
+}
+
+// upsertBatch upserts the documents and returns an error if any of them
+// failed
+func (c *Client) upsertBatch(ctx context.Context, upsertDocumentsReq *UpsertDocumentsRequest) error {
+	upsertDocumentsResp, err := c.UpsertDocumentsWithContext(ctx, upsertDocumentsReq)
+	if err != nil {
+		return err
+	}
+	if upsertDocumentsResp.Errors {
+		return fmt.Errorf("error upserting documents to %s: %d documents failed",
+			upsertDocumentsReq.IndexName, countFailedItems(upsertDocumentsResp.Items))
+	}
+	return nil
+}
+
+// stripDocumentMetadata returns the document without the fields added by
+// Marqo, such as _score and _highlights, except _id
+func stripDocumentMetadata(document map[string]interface{}) map[string]interface{} {
+	stripped := make(map[string]interface{}, len(document))
+	for key, value := range document {
+		if strings.HasPrefix(key, "_") && key != "_id" {
+			continue
+		}
+		stripped[key] = value
+	}
+	return stripped
+}
+
+// countFailedItems returns the number of items which were not upserted
+func countFailedItems(items []Item) int {
+	failed := 0
+	for _, item := range items {
+		if item.Status >= 400 {
+			failed++
+		}
+	}
+	return failed
+}
+
+// compareDocumentCounts returns an error if the new index does not hold as
+// many documents as the old index, or as were copied if only the documents
+// of DocumentIDs were
+func (c *Client) compareDocumentCounts(ctx context.Context, reindexReq *ReindexRequest, oldIndex string, copied int) error {
+	// an alias may shadow the old index
+	ctx = withPhysicalIndex(ctx)
+	newIndex := reindexReq.NewIndex.IndexName
+	newStats, err := c.GetIndexStatsWithContext(ctx, &GetIndexStatsRequest{IndexName: newIndex})
+	if err != nil {
+		return err
+	}
+	if len(reindexReq.DocumentIDs) > 0 {
+		if newStats.NumberOfDocuments != copied {
+			return fmt.Errorf("error validating index %s: %d documents, %d were copied", newIndex,
+				newStats.NumberOfDocuments, copied)
+		}
+		return nil
+	}
+	oldStats, err := c.GetIndexStatsWithContext(ctx, &GetIndexStatsRequest{IndexName: oldIndex})
+	if err != nil {
+		return err
+	}
+	if oldStats.NumberOfDocuments != newStats.NumberOfDocuments {
+		return fmt.Errorf("error validating index %s: %d documents, %s has %d", newIndex,
+			newStats.NumberOfDocuments, oldIndex, oldStats.NumberOfDocuments)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// RetryPolicy configures how failed requests are retried.
+//
+// Idempotent operations (e.g. Search, GetDocument, GetIndexStats) are retried
+// on RetryableStatusCodes and, if RetryNetworkErrors is set, on network
+// errors. Non-idempotent operations (e.g. CreateIndex, UpsertDocuments) are
+// only retried when the request is known not to have been processed: on
+// NonIdempotentStatusCodes and on failures to connect to the server.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one (default: 3)
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubled for every
+	// following retry (default: 100ms)
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts (default: 5s).
+	// If the server asks for a longer wait with a Retry-After header, the
+	// last response is returned instead of retrying.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff which is randomised, between 0 and 1 (default: 0.5)
+	Jitter float64
+	// RetryableStatusCodes are the status codes retried for idempotent
+	// operations (default: 429, 502, 503, 504)
+	RetryableStatusCodes []int
+	// NonIdempotentStatusCodes are the status codes retried for non-idempotent
+	// operations. Only use codes which guarantee that the server did not
+	// process the request (default: 429, 503)
+	NonIdempotentStatusCodes []int
+	// RetryNetworkErrors retries idempotent operations on network errors
+	RetryNetworkErrors bool
+	// IsRetryableError overrides the classification of network errors, it is
+	// called with the operation idempotency and the error of the attempt
+	IsRetryableError func(idempotent bool, err error) bool
+}
+
+// DefaultRetryPolicy returns the retry policy used for the zero fields of
+// the policy passed to WithRetryPolicy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.5,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		NonIdempotentStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusServiceUnavailable,
+		},
+		RetryNetworkErrors: true,
+	}
+}
+
+// WithRetryPolicy enables retries of failed requests with the given policy.
+// Zero fields of the policy are set from DefaultRetryPolicy, pass an empty
+// non-nil slice to disable retries on status codes.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithRetryPolicy(marqo.RetryPolicy{
+//	        MaxAttempts:        5,
+//	        RetryNetworkErrors: true,
+//	    }),
+//	)
+func WithRetryPolicy(policy RetryPolicy) func(*Client) {
+	return func(c *Client) {
+		defaults := DefaultRetryPolicy()
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaults.MaxAttempts
+		}
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = defaults.InitialBackoff
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = defaults.MaxBackoff
+		}
+		if policy.Jitter <= 0 || policy.Jitter > 1 {
+			policy.Jitter = defaults.Jitter
+		}
+		if policy.RetryableStatusCodes == nil {
+			policy.RetryableStatusCodes = defaults.RetryableStatusCodes
+		}
+		if policy.NonIdempotentStatusCodes == nil {
+			policy.NonIdempotentStatusCodes = defaults.NonIdempotentStatusCodes
+		}
+		c.retryPolicy = &policy
+	}
+}
+
+// roundTrip is the round trip wrapper which retries the request according
+// to the policy
+func (p *RetryPolicy) roundTrip(next req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		ctx := r.Context()
+		op := operationFromContext(ctx)
+		for attempt := 1; ; attempt++ {
+			resp, err := next.RoundTrip(r)
+			if attempt >= p.MaxAttempts || !p.shouldRetry(op.idempotent, resp, err) {
+				return resp, err
+			}
+
+			wait := p.backoff(attempt)
+			if retryAfter, ok := parseRetryAfter(resp); ok {
+				if retryAfter > p.MaxBackoff {
+					return resp, err
+				}
+				if retryAfter > wait {
+					wait = retryAfter
+				}
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return resp, err
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return resp, err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// shouldRetry reports whether the attempt which returned resp and err
+// should be retried
+func (p *RetryPolicy) shouldRetry(idempotent bool, resp *req.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+			errors.Is(err, ErrCircuitOpen) {
+			return false
+		}
+		if p.IsRetryableError != nil {
+			return p.IsRetryableError(idempotent, err)
+		}
+		if idempotent {
+			return p.RetryNetworkErrors
+		}
+		return isConnectError(err)
+	}
+	if resp == nil || resp.Response == nil {
+		return false
+	}
+
+	statusCodes := p.RetryableStatusCodes
+	if !idempotent {
+		statusCodes = p.NonIdempotentStatusCodes
+	}
+	for _, code := range statusCodes {
+		if resp.Response.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the jittered exponential backoff before the given retry
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(2, float64(attempt-1))
+	if backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	// nolint:gosec // jitter does not need a secure random source
+	jittered := backoff*(1-p.Jitter) + rand.Float64()*backoff*p.Jitter
+	return time.Duration(jittered)
+}
+
+// isConnectError reports whether err happened while connecting to the
+// server, i.e. before the request was sent
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter parses the Retry-After header of the response, which is
+// either a number of seconds or an HTTP date
+func parseRetryAfter(resp *req.Response) (time.Duration, bool) {
+	if resp == nil || resp.Response == nil {
+		return 0, false
+	}
+	value := resp.Response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package marqo
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// getMockServerForRetry returns a server which fails with failureCode for
+// the first failures requests and succeeds afterwards
+func getMockServerForRetry(failures int32, failureCode int, retryAfter string, attempts *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(attempts, 1) <= failures {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(failureCode)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`
+			{
+				"acknowledged": true,
+				"shards_acknowledged": true,
+				"index": "test",
+				"numberOfDocuments": 2,
+				"numberOfVectors": 3
+			}`))
+		}))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{
+			AddSource: true,
+			Level:     slog.LevelError,
+		}))
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}
+	tests := []struct {
+		name         string
+		opt          []Options
+		failures     int32
+		failureCode  int
+		retryAfter   string
+		createIndex  bool
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:         "no retry policy does not retry",
+			failures:     1,
+			failureCode:  http.StatusServiceUnavailable,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "idempotent operation is retried on 502",
+			opt:          []Options{WithRetryPolicy(policy)},
+			failures:     2,
+			failureCode:  http.StatusBadGateway,
+			wantAttempts: 3,
+			wantErr:      false,
+		},
+		{
+			name:         "retries stop after max attempts",
+			opt:          []Options{WithRetryPolicy(policy)},
+			failures:     5,
+			failureCode:  http.StatusServiceUnavailable,
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "non idempotent operation is not retried on 502",
+			opt:          []Options{WithRetryPolicy(policy)},
+			failures:     1,
+			failureCode:  http.StatusBadGateway,
+			createIndex:  true,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "non idempotent operation is retried on 429",
+			opt:          []Options{WithRetryPolicy(policy)},
+			failures:     1,
+			failureCode:  http.StatusTooManyRequests,
+			createIndex:  true,
+			wantAttempts: 2,
+			wantErr:      false,
+		},
+		{
+			name:         "retry after within max backoff is honoured",
+			opt:          []Options{WithRetryPolicy(policy)},
+			failures:     1,
+			failureCode:  http.StatusTooManyRequests,
+			retryAfter:   "0",
+			wantAttempts: 2,
+			wantErr:      false,
+		},
+		{
+			name:         "retry after beyond max backoff gives up",
+			opt:          []Options{WithRetryPolicy(policy)},
+			failures:     1,
+			failureCode:  http.StatusTooManyRequests,
+			retryAfter:   "120",
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			mockServer := getMockServerForRetry(tt.failures, tt.failureCode, tt.retryAfter, &attempts)
+			defer mockServer.Close()
+			c, err := NewClient(mockServer.URL, append(tt.opt, WithLogger(logger))...)
+			if err != nil {
+				t.Errorf("Client.Connect() error = %v", err)
+				return
+			}
+			if tt.createIndex {
+				_, err = c.CreateIndex(&CreateIndexRequest{IndexName: "test"})
+			} else {
+				_, err = c.GetIndexStats(&GetIndexStatsRequest{IndexName: "test"})
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", got, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package marqo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// schemaTag is the struct tag read by FieldsFromStruct
+const schemaTag = "marqo"
+
+// fieldTypeKinds are the Go kinds which can be stored in a field type,
+// slices are checked by their element kind
+var fieldTypeKinds = map[string][]reflect.Kind{
+	FieldTypeText:         {reflect.String},
+	FieldTypeImagePointer: {reflect.String},
+	FieldTypeInt: {reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64},
+	FieldTypeLong: {reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64},
+	FieldTypeFloat:        {reflect.Float32, reflect.Float64},
+	FieldTypeDouble:       {reflect.Float32, reflect.Float64},
+	FieldTypeBool:         {reflect.Bool},
+	FieldTypeCustomVector: {reflect.Struct, reflect.Map},
+}
+
+// arrayFieldTypes maps the array field types to the type of their elements
+var arrayFieldTypes = map[string]string{
+	FieldTypeArrayText:   FieldTypeText,
+	FieldTypeArrayInt:    FieldTypeInt,
+	FieldTypeArrayFloat:  FieldTypeFloat,
+	FieldTypeArrayLong:   FieldTypeLong,
+	FieldTypeArrayDouble: FieldTypeDouble,
+}
+
+// FieldsFromStruct derives the fields and tensor fields of a structured
+// index from the marqo tags of the struct, or pointer to struct, document.
+//
+// The tag is a comma separated list of the field name, the field type and
+// options:
+//
+//	type Product struct {
+//	    ID       string    `json:"_id"`
+//	    Title    string    `json:"title" marqo:",,lexical_search,tensor"`
+//	    Image    string    `marqo:"image,image_pointer,tensor"`
+//	    Price    float32   `marqo:"price,,filter,score_modifier"`
+//	    Tags     []string  `marqo:"tags,,filter"`
+//	    Combined struct{}  `json:"-" marqo:"combined,multimodal_combination,tensor,dependents=title:0.3|image:0.7"`
+//	}
+//
+// The name defaults to the json name of the field, then to its Go name.
+// The type defaults to the one of the Go type: string is text, int8 to
+// int32 are int, other integers are long, float32 is float, float64 is
+// double, bool is bool and slices of these are arrays, except []byte which
+// is text as it is encoded as a base64 string. Options are the
+// field features, "tensor" to add the field to the tensor fields and
+// "dependents" with the weighted dependent fields of a multimodal
+// combination field, whose Go type is not checked.
+//
+// Fields without a marqo tag or with the tag "-" are skipped, embedded
+// structs are flattened. Unsupported Go types, invalid tags and structs
+// which embed themselves are reported together and match
+// ErrInvalidArgument.
+func FieldsFromStruct(document interface{}) ([]FieldDefinition, []string, error) {
+	t := reflect.TypeOf(document)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("%w: document must be a struct, got %v", ErrInvalidArgument, t)
+	}
+
+	var (
+		fields       []FieldDefinition
+		tensorFields []string
+		errs         []error
+	)
+	// visiting are the structs being walked, an embedded struct among them
+	// embeds itself
+	visiting := map[reflect.Type]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		visiting[t] = true
+		defer delete(visiting, t)
+		for i := 0; i < t.NumField(); i++ {
+			structField := t.Field(i)
+			tag, tagged := structField.Tag.Lookup(schemaTag)
+			if structField.Anonymous && !tagged {
+				embedded := structField.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+				switch {
+				case embedded.Kind() != reflect.Struct:
+				case visiting[embedded]:
+					errs = append(errs, fmt.Errorf("%w: field %s: struct %s embeds itself", ErrInvalidArgument,
+						structField.Name, embedded))
+				default:
+					walk(embedded)
+				}
+				continue
+			}
+			if !tagged || tag == "-" || !structField.IsExported() {
+				continue
+			}
+
+			field, tensor, err := parseFieldTag(structField, tag)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w: field %s: %v", ErrInvalidArgument, structField.Name, err))
+				continue
+			}
+			fields = append(fields, field)
+			if tensor {
+				tensorFields = append(tensorFields, field.Name)
+			}
+		}
+	}
+	walk(t)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+	return fields, tensorFields, nil
+}
+
+// NewStructuredIndexRequest returns the request to create a structured
+// index with the fields derived by FieldsFromStruct from document. The
+// fields are validated like in CreateIndex.
+//
+// Example usage:
+//
+//	createIndexReq, err := marqo.NewStructuredIndexRequest("products", Product{})
+//	if err != nil {
+//	    log.Fatalf("Invalid product schema: %v", err)
+//	}
+//	resp, err := client.CreateIndex(createIndexReq)
+func NewStructuredIndexRequest(indexName string, document interface{}) (*CreateIndexRequest, error) {
+	fields, tensorFields, err := FieldsFromStruct(document)
+	if err != nil {
+		return nil, err
+	}
+	indexType := IndexTypeStructured
+	createIndexReq := &CreateIndexRequest{
+		IndexName:    indexName,
+		Type:         &indexType,
+		AllFields:    fields,
+		TensorFields: tensorFields,
+	}
+	if err := validateIndexSchema(createIndexReq); err != nil {
+		return nil, err
+	}
+	return createIndexReq, nil
+}
+
+// parseFieldTag returns the field definition of structField from its marqo
+// tag, and whether it is a tensor field
+func parseFieldTag(structField reflect.StructField, tag string) (FieldDefinition, bool, error) {
+	parts := strings.Split(tag, ",")
+	field := FieldDefinition{Name: parts[0]}
+	if field.Name == "" {
+		field.Name = structField.Name
+		if name, _, _ := strings.Cut(structField.Tag.Get("json"), ","); name != "" && name != "-" {
+			field.Name = name
+		}
+	}
+
+	tensor := false
+	if len(parts) > 1 {
+		field.Type = parts[1]
+	}
+	for _, option := range parts[min(len(parts), 2):] {
+		switch {
+		case option == "tensor":
+			tensor = true
+		case strings.HasPrefix(option, "dependents="):
+			dependents, err := parseDependentFields(strings.TrimPrefix(option, "dependents="))
+			if err != nil {
+				return field, false, err
+			}
+			field.DependentFields = dependents
+		case option == FieldFeatureLexicalSearch || option == FieldFeatureFilter || option == FieldFeatureScoreModifier:
+			field.Features = append(field.Features, option)
+		default:
+			return field, false, fmt.Errorf("unknown option %q", option)
+		}
+	}
+
+	goType := structField.Type
+	for goType.Kind() == reflect.Pointer {
+		goType = goType.Elem()
+	}
+	if field.Type == "" {
+		inferred, ok := inferFieldType(goType)
+		if !ok {
+			return field, false, fmt.Errorf("unsupported Go type %s", structField.Type)
+		}
+		field.Type = inferred
+		return field, tensor, nil
+	}
+	if !fieldTypeAccepts(field.Type, goType) {
+		return field, false, fmt.Errorf("type %s cannot be stored in a %s field", structField.Type, field.Type)
+	}
+	return field, tensor, nil
+}
+
+// parseDependentFields parses the dependent fields of a multimodal
+// combination field, e.g. "title:0.3|image:0.7"
+func parseDependentFields(value string) (map[string]float64, error) {
+	dependents := make(map[string]float64)
+	for _, dependent := range strings.Split(value, "|") {
+		name, weight, ok := strings.Cut(dependent, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid dependent field %q, want name:weight", dependent)
+		}
+		parsed, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight of dependent field %q: %v", name, err)
+		}
+		dependents[name] = parsed
+	}
+	return dependents, nil
+}
+
+// inferFieldType returns the field type of the Go type t
+func inferFieldType(t reflect.Type) (string, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return FieldTypeText, true
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return FieldTypeInt, true
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return FieldTypeLong, true
+	case reflect.Float32:
+		return FieldTypeFloat, true
+	case reflect.Float64:
+		return FieldTypeDouble, true
+	case reflect.Bool:
+		return FieldTypeBool, true
+	case reflect.Slice, reflect.Array:
+		if isByteSlice(t) {
+			return FieldTypeText, true
+		}
+		elem, ok := inferFieldType(t.Elem())
+		if !ok || elem == FieldTypeBool {
+			return "", false
+		}
+		for arrayType, elemType := range arrayFieldTypes {
+			if elemType == elem {
+				return arrayType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fieldTypeAccepts reports whether the Go type t can be stored in a field of
+// fieldType
+func fieldTypeAccepts(fieldType string, t reflect.Type) bool {
+	if fieldType == FieldTypeMultimodalCombination {
+		return true
+	}
+	if isByteSlice(t) {
+		return fieldType == FieldTypeText
+	}
+	if elemType, ok := arrayFieldTypes[fieldType]; ok {
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return false
+		}
+		return fieldTypeAccepts(elemType, t.Elem())
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, kind := range fieldTypeKinds[fieldType] {
+		if t.Kind() == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// isByteSlice reports whether t is a []byte, which encoding/json encodes as
+// a base64 string
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
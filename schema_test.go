@@ -0,0 +1,163 @@
+package marqo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaBase struct {
+	CreatedAt int64 `json:"created_at" marqo:",,filter,score_modifier"`
+}
+
+type schemaProduct struct {
+	schemaBase
+	ID        string             `json:"_id"`
+	Title     string             `json:"title,omitempty" marqo:",,lexical_search,tensor"`
+	Image     *string            `marqo:"image,image_pointer,tensor"`
+	Price     float32            `marqo:"price,,filter,score_modifier"`
+	Rating    float64            `marqo:"rating"`
+	Stock     int32              `marqo:"stock,long"`
+	Tags      []string           `marqo:"tags,,filter"`
+	OnSale    bool               `marqo:"on_sale,,filter"`
+	Embedding map[string]float64 `marqo:"embedding,custom_vector,tensor"`
+	Combined  struct{}           `json:"-" marqo:"combined,multimodal_combination,tensor,dependents=title:0.3|image:0.7"`
+	Internal  string             `json:"internal" marqo:"-"`
+}
+
+// schemaNode embeds itself directly, schemaLeft through schemaRight
+type schemaNode struct {
+	*schemaNode
+	Name string `marqo:"name"`
+}
+
+type schemaLeft struct {
+	*schemaRight
+	Left string `marqo:"left"`
+}
+
+type schemaRight struct {
+	*schemaLeft
+	Right string `marqo:"right"`
+}
+
+func TestFieldsFromStruct(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     interface{}
+		wantFields   []FieldDefinition
+		wantTensor   []string
+		wantErr      []string
+		wantIndexErr bool
+	}{
+		{
+			name:     "annotated struct",
+			document: &schemaProduct{},
+			wantFields: []FieldDefinition{
+				{Name: "created_at", Type: FieldTypeLong, Features: []string{FieldFeatureFilter, FieldFeatureScoreModifier}},
+				{Name: "title", Type: FieldTypeText, Features: []string{FieldFeatureLexicalSearch}},
+				{Name: "image", Type: FieldTypeImagePointer},
+				{Name: "price", Type: FieldTypeFloat, Features: []string{FieldFeatureFilter, FieldFeatureScoreModifier}},
+				{Name: "rating", Type: FieldTypeDouble},
+				{Name: "stock", Type: FieldTypeLong},
+				{Name: "tags", Type: FieldTypeArrayText, Features: []string{FieldFeatureFilter}},
+				{Name: "on_sale", Type: FieldTypeBool, Features: []string{FieldFeatureFilter}},
+				{Name: "embedding", Type: FieldTypeCustomVector},
+				{Name: "combined", Type: FieldTypeMultimodalCombination, DependentFields: map[string]float64{"title": 0.3, "image": 0.7}},
+			},
+			wantTensor: []string{"title", "image", "embedding", "combined"},
+		},
+		{
+			name: "byte slices are text",
+			document: struct {
+				Data   []byte   `marqo:"data,,lexical_search"`
+				Pixels [4]uint8 `marqo:"pixels"`
+			}{},
+			wantFields: []FieldDefinition{
+				{Name: "data", Type: FieldTypeText, Features: []string{FieldFeatureLexicalSearch}},
+				{Name: "pixels", Type: FieldTypeArrayInt},
+			},
+		},
+		{
+			name:     "not a struct",
+			document: map[string]interface{}{},
+			wantErr:  []string{"document must be a struct"},
+		},
+		{
+			name: "unsupported types and invalid tags",
+			document: struct {
+				Meta    map[string]string `marqo:"meta"`
+				Flags   []bool            `marqo:"flags"`
+				Count   string            `marqo:"count,int"`
+				Blob    []byte            `marqo:"blob,array<int>"`
+				Title   string            `marqo:"title,,sortable"`
+				Mixed   struct{}          `marqo:"mixed,multimodal_combination,dependents=title"`
+				Weights struct{}          `marqo:"weights,multimodal_combination,dependents=title:heavy"`
+			}{},
+			wantErr: []string{
+				"field Meta: unsupported Go type map[string]string",
+				"field Flags: unsupported Go type []bool",
+				"field Count: type string cannot be stored in a int field",
+				"field Blob: type []uint8 cannot be stored in a array<int> field",
+				`field Title: unknown option "sortable"`,
+				`field Mixed: invalid dependent field "title"`,
+				`field Weights: invalid weight of dependent field "title"`,
+			},
+		},
+		{
+			name:     "struct embedding itself",
+			document: schemaNode{},
+			wantErr:  []string{"field schemaNode: struct marqo.schemaNode embeds itself"},
+		},
+		{
+			name:     "structs embedding each other",
+			document: &schemaLeft{},
+			wantErr:  []string{"field schemaLeft: struct marqo.schemaLeft embeds itself"},
+		},
+		{
+			name: "invalid schema",
+			document: struct {
+				Price float32 `marqo:"price,,lexical_search,tensor"`
+			}{},
+			wantFields: []FieldDefinition{
+				{Name: "price", Type: FieldTypeFloat, Features: []string{FieldFeatureLexicalSearch}},
+			},
+			wantTensor:   []string{"price"},
+			wantIndexErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, tensorFields, err := FieldsFromStruct(tt.document)
+			if (err != nil) != (len(tt.wantErr) > 0) {
+				t.Fatalf("FieldsFromStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidArgument) {
+					t.Errorf("FieldsFromStruct() error = %v, want ErrInvalidArgument", err)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("FieldsFromStruct() error = %v, want %q", err, want)
+					}
+				}
+				return
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("FieldsFromStruct() fields = %+v, want %+v", fields, tt.wantFields)
+			}
+			if !reflect.DeepEqual(tensorFields, tt.wantTensor) {
+				t.Errorf("FieldsFromStruct() tensor fields = %v, want %v", tensorFields, tt.wantTensor)
+			}
+
+			createIndexReq, err := NewStructuredIndexRequest("products", tt.document)
+			if (err != nil) != tt.wantIndexErr {
+				t.Fatalf("NewStructuredIndexRequest() error = %v, wantErr %v", err, tt.wantIndexErr)
+			}
+			if err == nil && (*createIndexReq.Type != IndexTypeStructured || createIndexReq.IndexName != "products") {
+				t.Errorf("NewStructuredIndexRequest() = %+v", createIndexReq)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -173,7 +174,8 @@ func setDefaultSearchRequest(searchRequest *SearchRequest) {
 //
 // The function performs the following steps:
 // 1. Validates the searchReq parameter.
-// 2. Sends a GET request to the server with the search query as query parameters.
+// 2. Sends a POST request to the server with the search query, as query parameters
+// and body for Marqo 1.x and as body only for Marqo 2.x.
 // 3. Checks the response status code and logs any errors.
 // 4. Returns the response from the server if the operation is successful, otherwise returns an error.
 //
@@ -189,14 +191,27 @@ func setDefaultSearchRequest(searchRequest *SearchRequest) {
 //	}
 //	fmt.Printf("SearchResponse: %+v\n", resp)
 func (c *Client) Search(searchReq *SearchRequest) (*SearchResponse, error) {
-	logger := c.logger.With("method", "Search")
+	return c.SearchWithContext(context.Background(), searchReq)
+}
+
+// SearchWithContext is like Search but sends the request with ctx.
+func (c *Client) SearchWithContext(ctx context.Context, searchReq *SearchRequest) (_ *SearchResponse, err error) {
+	ctx, span := c.startSpan(ctx, "Search")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("Search")
+	c.setDefaultIndex(&searchReq.IndexName)
 	setDefaultSearchRequest(searchReq)
-	err := validate.Struct(searchReq)
+	err = validate.Struct(searchReq)
 	if err != nil {
 		logger.Error("error validating search request",
 			"error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, searchReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var searchResp SearchResponse
 	queryParams := map[string]string{}
@@ -252,26 +267,35 @@ func (c *Client) Search(searchReq *SearchRequest) (*SearchResponse, error) {
 		queryParams["telemetry"] = strconv.FormatBool(*searchReq.Telemetry)
 	}
 
+	dialect, err := c.DetectDialect(ctx)
+	if err != nil {
+		logger.Error("error detecting dialect", "error", err)
+		return nil, err
+	}
+	var body interface{} = searchReq
+	if dialect == DialectV2 {
+		body, queryParams = newSearchRequestV2(searchReq)
+	}
+
 	// Remove index name from body
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "Search", indexName, searchReq).
 		SetQueryParams(queryParams).
-		SetBody(searchReq).
+		SetBody(body).
 		SetSuccessResult(&searchResp).
-		Post(c.reqClient.BaseURL + "/indexes/" + searchReq.IndexName + "/search")
+		Post(c.reqClient.BaseURL + "/indexes/" + indexName + "/search")
 	if err != nil {
 		logger.Error("error searching", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != 200 {
-		logger.Error("error searching", "status_code",
-			resp.Response.StatusCode)
-		return nil, fmt.Errorf(
-			"error searching: status code: %v",
-			resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error searching", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error searching: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response search: %+v\n",
-		searchResp))
+	c.logResponse(ctx, logger, resp, "search done",
+		"index", indexName,
+		"hits", len(searchResp.Hits),
+		"processing_time_ms", searchResp.ProcessingTimeMS)
 	return &searchResp, nil
 }
@@ -1,6 +1,8 @@
 package marqo
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -13,6 +15,76 @@ type GetIndexSettingsRequest struct {
 // GetIndexSettingsResponse is the response from the server
 type GetIndexSettingsResponse struct {
 	IndexDefaults *IndexDefaults `json:"index_defaults"`
+	// NumberOfShards and NumberOfReplicas are only reported by Marqo 1.x
+	NumberOfShards   *int `json:"number_of_shards,omitempty"`
+	NumberOfReplicas *int `json:"number_of_replicas,omitempty"`
+
+	// Marqo 2.x only settings
+
+	// Type of the index, IndexTypeUnstructured or IndexTypeStructured
+	Type string `json:"-"`
+	// AllFields are the fields of a structured index
+	AllFields []FieldDefinition `json:"-"`
+	// TensorFields are the vectorised fields of a structured index
+	TensorFields []string `json:"-"`
+	// VectorNumericType is the numeric type of the vectors
+	VectorNumericType *string `json:"-"`
+}
+
+// UnmarshalJSON decodes the settings of Marqo 1.x, which are nested in
+// index_defaults, and of Marqo 2.x, which are camelCase top level fields.
+// Marqo 2.x space types such as "prenormalized-angular" are kept as is.
+func (r *GetIndexSettingsResponse) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["index_defaults"]; ok {
+		type settingsV1 GetIndexSettingsResponse
+		return json.Unmarshal(data, (*settingsV1)(r))
+	}
+	if _, ok := fields["model"]; !ok {
+		return nil
+	}
+
+	var settings createIndexRequestV2
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return err
+	}
+	r.Type = settings.Type
+	r.AllFields = settings.AllFields
+	r.TensorFields = settings.TensorFields
+	r.VectorNumericType = settings.VectorNumericType
+	r.IndexDefaults = &IndexDefaults{
+		TreatURLsAndPointersAsImages: settings.TreatURLsAndPointersAsImages,
+		Model:                        settings.Model,
+		ModelProperties:              settings.ModelProperties,
+		NormalizeEmbeddings:          settings.NormalizeEmbeddings,
+	}
+	if settings.TextPreprocessing != nil {
+		r.IndexDefaults.TextPreprocessing = &TextPreprocessing{
+			SplitLength:  settings.TextPreprocessing.SplitLength,
+			SplitOverlap: settings.TextPreprocessing.SplitOverlap,
+			SplitMethod:  settings.TextPreprocessing.SplitMethod,
+		}
+	}
+	if settings.ImagePreprocessing != nil {
+		r.IndexDefaults.ImagePreprocessing = &ImagePreprocessing{
+			PatchMethod: settings.ImagePreprocessing.PatchMethod,
+		}
+	}
+	if settings.ANNParameters != nil {
+		r.IndexDefaults.ANNParameters = &ANNParameters{
+			SpaceType: settings.ANNParameters.SpaceType,
+		}
+		if settings.ANNParameters.Parameters != nil {
+			r.IndexDefaults.ANNParameters.Parameters = &HSNWMethodParameters{
+				EFConstruction: settings.ANNParameters.Parameters.EFConstruction,
+				M:              settings.ANNParameters.Parameters.M,
+			}
+		}
+	}
+	return nil
 }
 
 // GetIndexSettings gets the index settings from the server.
@@ -45,27 +117,41 @@ type GetIndexSettingsResponse struct {
 //	}
 //	fmt.Printf("GetIndexSettingsResponse: %+v\n", resp)
 func (c *Client) GetIndexSettings(getIndexSettingsReq *GetIndexSettingsRequest) (*GetIndexSettingsResponse, error) {
-	logger := c.logger.With("method", "GetIndexSettings")
-	err := validate.Struct(getIndexSettingsReq)
+	return c.GetIndexSettingsWithContext(context.Background(), getIndexSettingsReq)
+}
+
+// GetIndexSettingsWithContext is like GetIndexSettings but sends the request with ctx.
+func (c *Client) GetIndexSettingsWithContext(ctx context.Context, getIndexSettingsReq *GetIndexSettingsRequest) (_ *GetIndexSettingsResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetIndexSettings")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetIndexSettings")
+	c.setDefaultIndex(&getIndexSettingsReq.IndexName)
+	err = validate.Struct(getIndexSettingsReq)
 	if err != nil {
 		logger.Error("error validating get index settings request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, getIndexSettingsReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var getIndexSettingsResp GetIndexSettingsResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetIndexSettings", indexName, getIndexSettingsReq).
 		SetSuccessResult(&getIndexSettingsResp).
-		Get(c.reqClient.BaseURL + "/indexes/" + getIndexSettingsReq.IndexName + "/settings")
+		Get(c.reqClient.BaseURL + "/indexes/" + indexName + "/settings")
 	if err != nil {
 		logger.Error("error getting index settings", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error getting index settings", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting index settings: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting index settings", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting index settings: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response index settings: %+v", getIndexSettingsResp))
+	c.logResponse(ctx, logger, resp, "got index settings",
+		"index", indexName)
 	return &getIndexSettingsResp, nil
 }
@@ -1,6 +1,7 @@
 package marqo
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -46,27 +47,43 @@ type GetIndexStatsResponse struct {
 //	}
 //	fmt.Printf("GetIndexStatsResponse: %+v\n", resp)
 func (c *Client) GetIndexStats(getIndexStatsReq *GetIndexStatsRequest) (*GetIndexStatsResponse, error) {
-	logger := c.logger.With("method", "GetIndexStats")
-	err := validate.Struct(getIndexStatsReq)
+	return c.GetIndexStatsWithContext(context.Background(), getIndexStatsReq)
+}
+
+// GetIndexStatsWithContext is like GetIndexStats but sends the request with ctx.
+func (c *Client) GetIndexStatsWithContext(ctx context.Context, getIndexStatsReq *GetIndexStatsRequest) (_ *GetIndexStatsResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetIndexStats")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetIndexStats")
+	c.setDefaultIndex(&getIndexStatsReq.IndexName)
+	err = validate.Struct(getIndexStatsReq)
 	if err != nil {
 		logger.Error("error validating get index stats request", "error", err)
 		return nil, err
 	}
+	indexName, err := c.resolveIndex(ctx, getIndexStatsReq.IndexName)
+	if err != nil {
+		logger.Error("error resolving index", "error", err)
+		return nil, err
+	}
 
 	var getIndexStatsResp GetIndexStatsResponse
-	resp, err := c.reqClient.
-		R().
+	resp, err := c.newRequest(ctx, "GetIndexStats", indexName, getIndexStatsReq).
 		SetSuccessResult(&getIndexStatsResp).
-		Get(c.reqClient.BaseURL + "/indexes/" + getIndexStatsReq.IndexName + "/stats")
+		Get(c.reqClient.BaseURL + "/indexes/" + indexName + "/stats")
 	if err != nil {
 		logger.Error("error getting index stats", "error", err)
 		return nil, err
 	}
 	if resp.Response.StatusCode != http.StatusOK {
-		logger.Error("error getting index stats", "status_code", resp.Response.StatusCode)
-		return nil, fmt.Errorf("error getting index stats: status code: %v", resp.Response.StatusCode)
+		apiErr := newAPIError(resp)
+		logger.Error("error getting index stats", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting index stats: %w", apiErr)
 	}
 
-	logger.Info(fmt.Sprintf("response index stats: %+v", getIndexStatsResp))
+	c.logResponse(ctx, logger, resp, "got index stats",
+		"index", indexName,
+		"documents", getIndexStatsResp.NumberOfDocuments,
+		"vectors", getIndexStatsResp.NumberOfVectors)
 	return &getIndexStatsResp, nil
 }
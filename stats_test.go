@@ -1,12 +1,15 @@
 package marqo
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/imroc/req/v3"
 	"github.com/stretchr/testify/suite"
@@ -114,3 +117,57 @@ func (suite *StatsTestSuite) TestClient_GetIndexStats() {
 		})
 	}
 }
+
+func (suite *StatsTestSuite) TestClient_GetIndexStatsWithContext() {
+	t := suite.T()
+	slowServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	defer slowServer.Close()
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	deadlineCtx, cancelDeadline := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelDeadline()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{
+			name:    "cancelled context aborts the request",
+			ctx:     cancelledCtx,
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "context deadline bounds the request",
+			ctx:     deadlineCtx,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(slowServer.URL, WithLogger(suite.Logger))
+			if err != nil {
+				t.Errorf("Client.Connect() error = %v", err)
+				return
+			}
+			got, err := c.GetIndexStatsWithContext(tt.ctx, &GetIndexStatsRequest{
+				IndexName: "test",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.GetIndexStatsWithContext() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				t.Errorf("Client.GetIndexStatsWithContext() = %v, want nil", got)
+			}
+		})
+	}
+}
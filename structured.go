@@ -0,0 +1,172 @@
+package marqo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Index types of Marqo 2.x
+const (
+	// IndexTypeUnstructured indexes any field of the documents (default)
+	IndexTypeUnstructured = "unstructured"
+	// IndexTypeStructured indexes the fields declared in AllFields only,
+	// which makes filtering faster
+	IndexTypeStructured = "structured"
+)
+
+// Field types of structured indexes
+const (
+	FieldTypeText                  = "text"
+	FieldTypeInt                   = "int"
+	FieldTypeFloat                 = "float"
+	FieldTypeLong                  = "long"
+	FieldTypeDouble                = "double"
+	FieldTypeBool                  = "bool"
+	FieldTypeArrayText             = "array<text>"
+	FieldTypeArrayInt              = "array<int>"
+	FieldTypeArrayFloat            = "array<float>"
+	FieldTypeArrayLong             = "array<long>"
+	FieldTypeArrayDouble           = "array<double>"
+	FieldTypeImagePointer          = "image_pointer"
+	FieldTypeMultimodalCombination = "multimodal_combination"
+	FieldTypeCustomVector          = "custom_vector"
+)
+
+// Field features of structured indexes
+const (
+	// FieldFeatureLexicalSearch makes the field searchable with LEXICAL search
+	FieldFeatureLexicalSearch = "lexical_search"
+	// FieldFeatureFilter makes the field usable in filter strings
+	FieldFeatureFilter = "filter"
+	// FieldFeatureScoreModifier makes the field usable in score modifiers
+	FieldFeatureScoreModifier = "score_modifier"
+)
+
+// Numeric types of the vectors of Marqo 2.x indexes
+const (
+	VectorNumericTypeFloat    = "float"
+	VectorNumericTypeBfloat16 = "bfloat16"
+)
+
+// FieldDefinition is a field of a structured index
+type FieldDefinition struct {
+	// Name of the field
+	Name string `json:"name" validate:"required"`
+	// Type of the field, one of the FieldType constants
+	Type string `json:"type" validate:"required"`
+	// Features of the field, FieldFeature constants
+	Features []string `json:"features,omitempty"`
+	// DependentFields are the fields combined by a multimodal_combination
+	// field, with their weights
+	DependentFields map[string]float64 `json:"dependentFields,omitempty"`
+}
+
+// fieldFeatures are the features supported by every field type
+var fieldFeatures = map[string]map[string]bool{
+	FieldTypeText:                  {FieldFeatureLexicalSearch: true, FieldFeatureFilter: true},
+	FieldTypeInt:                   {FieldFeatureFilter: true, FieldFeatureScoreModifier: true},
+	FieldTypeFloat:                 {FieldFeatureFilter: true, FieldFeatureScoreModifier: true},
+	FieldTypeLong:                  {FieldFeatureFilter: true, FieldFeatureScoreModifier: true},
+	FieldTypeDouble:                {FieldFeatureFilter: true, FieldFeatureScoreModifier: true},
+	FieldTypeBool:                  {FieldFeatureFilter: true},
+	FieldTypeArrayText:             {FieldFeatureLexicalSearch: true, FieldFeatureFilter: true},
+	FieldTypeArrayInt:              {FieldFeatureFilter: true},
+	FieldTypeArrayFloat:            {FieldFeatureFilter: true},
+	FieldTypeArrayLong:             {FieldFeatureFilter: true},
+	FieldTypeArrayDouble:           {FieldFeatureFilter: true},
+	FieldTypeImagePointer:          {FieldFeatureFilter: true},
+	FieldTypeMultimodalCombination: {},
+	FieldTypeCustomVector:          {FieldFeatureLexicalSearch: true, FieldFeatureFilter: true},
+}
+
+// tensorFieldTypes are the field types which can be tensor fields
+var tensorFieldTypes = map[string]bool{
+	FieldTypeText:                  true,
+	FieldTypeImagePointer:          true,
+	FieldTypeMultimodalCombination: true,
+	FieldTypeCustomVector:          true,
+}
+
+// validateIndexSchema checks the Marqo 2.x index type, fields and vector
+// numeric type of createIndexReq. All problems are reported at once and
+// match ErrInvalidArgument.
+func validateIndexSchema(createIndexReq *CreateIndexRequest) error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidArgument}, args...)...))
+	}
+
+	if createIndexReq.VectorNumericType != nil &&
+		*createIndexReq.VectorNumericType != VectorNumericTypeFloat &&
+		*createIndexReq.VectorNumericType != VectorNumericTypeBfloat16 {
+		invalid("unknown vector numeric type %q", *createIndexReq.VectorNumericType)
+	}
+
+	indexType := IndexTypeUnstructured
+	if createIndexReq.Type != nil {
+		indexType = *createIndexReq.Type
+	}
+	switch indexType {
+	case IndexTypeUnstructured:
+		if len(createIndexReq.AllFields) > 0 || len(createIndexReq.TensorFields) > 0 {
+			invalid("allFields and tensorFields are only supported by structured indexes")
+		}
+		return errors.Join(errs...)
+	case IndexTypeStructured:
+	default:
+		invalid("unknown index type %q", indexType)
+		return errors.Join(errs...)
+	}
+
+	if len(createIndexReq.AllFields) == 0 {
+		invalid("structured index needs at least one field in allFields")
+	}
+	fields := make(map[string]FieldDefinition, len(createIndexReq.AllFields))
+	for _, field := range createIndexReq.AllFields {
+		if _, ok := fields[field.Name]; ok {
+			invalid("duplicate field %q", field.Name)
+			continue
+		}
+		fields[field.Name] = field
+
+		features, ok := fieldFeatures[field.Type]
+		if !ok {
+			invalid("field %q has unknown type %q", field.Name, field.Type)
+			continue
+		}
+		for _, feature := range field.Features {
+			if !features[feature] {
+				invalid("field %q of type %s does not support feature %q", field.Name, field.Type, feature)
+			}
+		}
+		if field.Type == FieldTypeMultimodalCombination && len(field.DependentFields) == 0 {
+			invalid("multimodal combination field %q has no dependent fields", field.Name)
+		}
+		if field.Type != FieldTypeMultimodalCombination && len(field.DependentFields) > 0 {
+			invalid("field %q of type %s cannot have dependent fields", field.Name, field.Type)
+		}
+	}
+
+	for _, field := range createIndexReq.AllFields {
+		for dependent := range field.DependentFields {
+			dependentField, ok := fields[dependent]
+			switch {
+			case !ok:
+				invalid("dependent field %q of %q is not in allFields", dependent, field.Name)
+			case dependentField.Type != FieldTypeText && dependentField.Type != FieldTypeImagePointer:
+				invalid("dependent field %q of %q must be text or image_pointer, got %s", dependent, field.Name, dependentField.Type)
+			}
+		}
+	}
+
+	for _, name := range createIndexReq.TensorFields {
+		field, ok := fields[name]
+		switch {
+		case !ok:
+			invalid("tensor field %q is not in allFields", name)
+		case !tensorFieldTypes[field.Type]:
+			invalid("tensor field %q of type %s cannot be vectorised", name, field.Type)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,176 @@
+package marqo
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_validateIndexSchema(t *testing.T) {
+	structured := IndexTypeStructured
+	unstructured := IndexTypeUnstructured
+	unknown := "hybrid"
+	bfloat16 := VectorNumericTypeBfloat16
+	double := "double"
+	fields := []FieldDefinition{
+		{Name: "title", Type: FieldTypeText, Features: []string{FieldFeatureLexicalSearch, FieldFeatureFilter}},
+		{Name: "image", Type: FieldTypeImagePointer},
+		{Name: "price", Type: FieldTypeFloat, Features: []string{FieldFeatureFilter, FieldFeatureScoreModifier}},
+		{Name: "tags", Type: FieldTypeArrayText, Features: []string{FieldFeatureFilter}},
+		{Name: "in_stock", Type: FieldTypeBool, Features: []string{FieldFeatureFilter}},
+		{Name: "embedding", Type: FieldTypeCustomVector},
+		{Name: "combined", Type: FieldTypeMultimodalCombination, DependentFields: map[string]float64{"title": 0.4, "image": 0.6}},
+	}
+
+	tests := []struct {
+		name    string
+		req     *CreateIndexRequest
+		wantErr []string
+	}{
+		{
+			name: "unstructured by default",
+			req:  &CreateIndexRequest{IndexName: "test"},
+		},
+		{
+			name: "valid structured index",
+			req: &CreateIndexRequest{
+				IndexName:         "test",
+				Type:              &structured,
+				AllFields:         fields,
+				TensorFields:      []string{"title", "image", "combined", "embedding"},
+				VectorNumericType: &bfloat16,
+			},
+		},
+		{
+			name:    "unknown index type",
+			req:     &CreateIndexRequest{IndexName: "test", Type: &unknown},
+			wantErr: []string{`unknown index type "hybrid"`},
+		},
+		{
+			name:    "fields on unstructured index",
+			req:     &CreateIndexRequest{IndexName: "test", Type: &unstructured, TensorFields: []string{"title"}},
+			wantErr: []string{"only supported by structured indexes"},
+		},
+		{
+			name:    "unknown vector numeric type",
+			req:     &CreateIndexRequest{IndexName: "test", VectorNumericType: &double},
+			wantErr: []string{`unknown vector numeric type "double"`},
+		},
+		{
+			name:    "structured index without fields",
+			req:     &CreateIndexRequest{IndexName: "test", Type: &structured},
+			wantErr: []string{"at least one field"},
+		},
+		{
+			name: "invalid fields",
+			req: &CreateIndexRequest{
+				IndexName: "test",
+				Type:      &structured,
+				AllFields: []FieldDefinition{
+					{Name: "title", Type: FieldTypeText, Features: []string{FieldFeatureScoreModifier}},
+					{Name: "title", Type: FieldTypeText},
+					{Name: "size", Type: "decimal"},
+					{Name: "price", Type: FieldTypeInt, DependentFields: map[string]float64{"title": 1}},
+					{Name: "empty", Type: FieldTypeMultimodalCombination},
+					{Name: "combined", Type: FieldTypeMultimodalCombination, DependentFields: map[string]float64{"missing": 0.5, "price": 0.5}},
+				},
+				TensorFields: []string{"description", "price"},
+			},
+			wantErr: []string{
+				`field "title" of type text does not support feature "score_modifier"`,
+				`duplicate field "title"`,
+				`field "size" has unknown type "decimal"`,
+				`field "price" of type int cannot have dependent fields`,
+				`multimodal combination field "empty" has no dependent fields`,
+				`dependent field "missing" of "combined" is not in allFields`,
+				`dependent field "price" of "combined" must be text or image_pointer`,
+				`tensor field "description" is not in allFields`,
+				`tensor field "price" of type int cannot be vectorised`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIndexSchema(tt.req)
+			if (err != nil) != (len(tt.wantErr) > 0) {
+				t.Fatalf("validateIndexSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("validateIndexSchema() error = %v, want ErrInvalidArgument", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validateIndexSchema() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_CreateStructuredIndex(t *testing.T) {
+	structured := IndexTypeStructured
+	newRequest := func() *CreateIndexRequest {
+		return &CreateIndexRequest{
+			IndexName: "products",
+			Type:      &structured,
+			AllFields: []FieldDefinition{
+				{Name: "title", Type: FieldTypeText, Features: []string{FieldFeatureLexicalSearch}},
+				{Name: "price", Type: FieldTypeFloat, Features: []string{FieldFeatureFilter}},
+			},
+			TensorFields: []string{"title"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		wantErr error
+		want    func(body string) bool
+	}{
+		{
+			name:    "marqo 2.x",
+			version: "2.5.0",
+			want: func(body string) bool {
+				return strings.Contains(body, `"type":"structured"`) &&
+					strings.Contains(body, `"allFields":[{"name":"title","type":"text","features":["lexical_search"]},`+
+						`{"name":"price","type":"float","features":["filter"]}]`) &&
+					strings.Contains(body, `"tensorFields":["title"]`) &&
+					!strings.Contains(body, "treatUrlsAndPointersAsImages")
+			},
+		},
+		{
+			name:    "marqo 1.x",
+			version: "1.5.1",
+			wantErr: ErrUnsupportedOperation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests []dialectRequest
+			)
+			mockServer := getMockServerForDialect(tt.version, &mu, &requests)
+			defer mockServer.Close()
+
+			c, err := NewClient(mockServer.URL, WithDialect(DialectAuto))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			_, err = c.CreateIndex(newRequest())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Client.CreateIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.want != nil && !tt.want(requests[len(requests)-1].body) {
+				t.Errorf("Client.CreateIndex() body = %s", requests[len(requests)-1].body)
+			}
+		})
+	}
+}
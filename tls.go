@@ -0,0 +1,258 @@
+package marqo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+// ErrCertificatePinMismatch is returned when none of the server
+// certificates matches the pins set with WithSPKIPins
+var ErrCertificatePinMismatch = errors.New("marqo: server certificate does not match any pin")
+
+// tlsSettings holds the TLS options of the client
+type tlsSettings struct {
+	rootCAs *x509.CertPool
+	caFiles []string
+	// certificates are the static client certificates
+	certificates []tls.Certificate
+	// certFile and keyFile are the client certificate files, reloaded when they change
+	certFile string
+	keyFile  string
+	// minVersion is the minimum TLS version, 0 keeps the default
+	minVersion uint16
+	// pins are the base64 encoded sha256 hashes of the pinned public keys
+	pins []string
+}
+
+// tlsOptions returns the TLS settings of the client, creating them if needed
+func (c *Client) tlsOptions() *tlsSettings {
+	if c.tls == nil {
+		c.tls = &tlsSettings{}
+	}
+	return c.tls
+}
+
+// WithRootCAs sets the certificate authorities used to verify the server
+// certificate, e.g. for an on-prem Marqo with an internal CA
+func WithRootCAs(pool *x509.CertPool) func(*Client) {
+	return func(c *Client) {
+		c.tlsOptions().rootCAs = pool
+	}
+}
+
+// WithCAFile adds the PEM encoded certificate authorities in file to the
+// ones used to verify the server certificate. NewClient returns an error
+// if the file cannot be read.
+func WithCAFile(file string) func(*Client) {
+	return func(c *Client) {
+		c.tlsOptions().caFiles = append(c.tlsOptions().caFiles, file)
+	}
+}
+
+// WithClientCertificate sets the certificate presented to servers which
+// require mutual TLS
+func WithClientCertificate(certificate tls.Certificate) func(*Client) {
+	return func(c *Client) {
+		c.tlsOptions().certificates = append(c.tlsOptions().certificates, certificate)
+	}
+}
+
+// WithClientCertificateFiles sets the PEM encoded certificate and key files
+// presented to servers which require mutual TLS. The files are read again
+// on new connections after they change on disk, so rotated certificates
+// are picked up without restarting. NewClient returns an error if the
+// files cannot be loaded.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("https://marqo.internal:8882",
+//	    marqo.WithCAFile("/etc/marqo/ca.pem"),
+//	    marqo.WithClientCertificateFiles("/etc/marqo/client.pem", "/etc/marqo/client-key.pem"),
+//	    marqo.WithMinTLSVersion(tls.VersionTLS13),
+//	)
+func WithClientCertificateFiles(certFile, keyFile string) func(*Client) {
+	return func(c *Client) {
+		c.tlsOptions().certFile = certFile
+		c.tlsOptions().keyFile = keyFile
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version, e.g. tls.VersionTLS13
+func WithMinTLSVersion(version uint16) func(*Client) {
+	return func(c *Client) {
+		c.tlsOptions().minVersion = version
+	}
+}
+
+// WithSPKIPins pins the public key of the server. Each pin is the base64
+// encoded sha256 hash of a DER encoded SubjectPublicKeyInfo, and the
+// connection fails with ErrCertificatePinMismatch unless a certificate
+// of the server chain matches one of them. The pin of a certificate can
+// be computed with:
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func WithSPKIPins(pins ...string) func(*Client) {
+	return func(c *Client) {
+		c.tlsOptions().pins = append(c.tlsOptions().pins, pins...)
+	}
+}
+
+// apply sets the TLS options on config
+func (s *tlsSettings) apply(config *tls.Config) error {
+	if s.rootCAs != nil {
+		config.RootCAs = s.rootCAs
+	}
+	if len(s.caFiles) > 0 {
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+		for _, file := range s.caFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("error reading ca file: %w", err)
+			}
+			if !config.RootCAs.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificates found in ca file %q", file)
+			}
+		}
+	}
+	if len(s.certificates) > 0 {
+		config.Certificates = append(config.Certificates, s.certificates...)
+	}
+	if s.certFile != "" || s.keyFile != "" {
+		reloader := &certReloader{certFile: s.certFile, keyFile: s.keyFile}
+		if _, err := reloader.load(); err != nil {
+			return err
+		}
+		config.GetClientCertificate = reloader.getClientCertificate
+	}
+	if s.minVersion != 0 {
+		config.MinVersion = s.minVersion
+	}
+	if len(s.pins) > 0 {
+		pins := make([][]byte, 0, len(s.pins))
+		for _, pin := range s.pins {
+			hash, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(hash) != sha256.Size {
+				return fmt.Errorf("invalid spki pin %q: want a base64 encoded sha256 hash", pin)
+			}
+			pins = append(pins, hash)
+		}
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPins(cs, pins)
+		}
+	}
+	return nil
+}
+
+// verifyPins checks that a certificate of the server chain matches a pin
+func verifyPins(cs tls.ConnectionState, pins [][]byte) error {
+	for _, cert := range cs.PeerCertificates {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if bytes.Equal(hash[:], pin) {
+				return nil
+			}
+		}
+	}
+	return ErrCertificatePinMismatch
+}
+
+// certReloader loads the client certificate from disk and reloads it when
+// the files change
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+}
+
+// load returns the certificate, reading the files again if they changed
+// since the last load
+func (r *certReloader) load() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, fmt.Errorf("error reading client certificate: %w", err)
+	}
+	if r.certificate != nil && !modTime.After(r.modTime) {
+		return r.certificate, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading client certificate: %w", err)
+	}
+	r.certificate = &certificate
+	r.modTime = modTime
+	return r.certificate, nil
+}
+
+// latestModTime returns the latest modification time of the files
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// getClientCertificate is the tls.Config callback which returns the
+// current client certificate. The last loaded certificate is kept if the
+// files cannot be read, e.g. while they are being replaced.
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	certificate, err := r.load()
+	if err != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.certificate != nil {
+			return r.certificate, nil
+		}
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// setTLSConfig applies the TLS options to the transport of reqClient
+func (c *Client) setTLSConfig() error {
+	if c.tls == nil {
+		return nil
+	}
+	httpClient := c.reqClient.GetClient()
+	switch transport := httpClient.Transport.(type) {
+	case *req.Transport:
+		return c.tls.apply(c.reqClient.GetTLSClientConfig())
+	case *http.Transport:
+		transport = transport.Clone()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		if err := c.tls.apply(transport.TLSClientConfig); err != nil {
+			return err
+		}
+		httpClient.Transport = transport
+		return nil
+	default:
+		return fmt.Errorf("tls options need an *http.Transport, got %T", httpClient.Transport)
+	}
+}
@@ -0,0 +1,312 @@
+package marqo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TLSTestSuite struct {
+	suite.Suite
+	Logger *slog.Logger
+}
+
+func (suite *TLSTestSuite) SetupSuite() {
+	suite.Logger = slog.New(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{
+			AddSource: true,
+			Level:     slog.LevelError,
+		}))
+}
+
+func TestTLSTestSuite(t *testing.T) {
+	suite.Run(t, new(TLSTestSuite))
+}
+
+// generateClientCertificate returns a self signed client certificate and
+// its PEM encoded certificate and key
+func generateClientCertificate(serial int64) (*x509.Certificate, []byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "marqo-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cert,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
+
+// getMockServerForTLS returns a TLS server which records the serial number
+// of the client certificate. Client certificates are required if clientCAs
+// is not nil.
+func getMockServerForTLS(clientCAs *x509.CertPool, mu *sync.Mutex, serials *[]int64) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if len(r.TLS.PeerCertificates) > 0 {
+				mu.Lock()
+				*serials = append(*serials, r.TLS.PeerCertificates[0].SerialNumber.Int64())
+				mu.Unlock()
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(`{"results": []}`))
+		}))
+	if clientCAs != nil {
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		}
+	}
+	server.StartTLS()
+	return server
+}
+
+// serverCertPool returns a pool with the certificate of the mock server
+func serverCertPool(server *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return pool
+}
+
+func (suite *TLSTestSuite) TestRootCAs() {
+	t := suite.T()
+	var (
+		mu      sync.Mutex
+		serials []int64
+	)
+	mockServer := getMockServerForTLS(nil, &mu, &serials)
+	defer mockServer.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: mockServer.Certificate().Raw})
+	suite.Require().NoError(os.WriteFile(caFile, caPEM, 0o600))
+
+	tests := []struct {
+		name    string
+		opt     []Options
+		wantErr bool
+	}{
+		{
+			name:    "unknown authority",
+			opt:     nil,
+			wantErr: true,
+		},
+		{
+			name: "root ca pool",
+			opt:  []Options{WithRootCAs(serverCertPool(mockServer))},
+		},
+		{
+			name: "ca file",
+			opt:  []Options{WithCAFile(caFile)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(mockServer.URL, append(tt.opt, WithLogger(suite.Logger))...)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			_, err = c.ListIndexes()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.ListIndexes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *TLSTestSuite) TestMinTLSVersion() {
+	t := suite.T()
+	mockServer := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	mockServer.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	mockServer.StartTLS()
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL,
+		WithLogger(suite.Logger),
+		WithRootCAs(serverCertPool(mockServer)),
+		WithMinTLSVersion(tls.VersionTLS13),
+	)
+	suite.Require().NoError(err)
+	if _, err := c.ListIndexes(); err == nil {
+		t.Errorf("Client.ListIndexes() error = nil, want protocol version error")
+	}
+}
+
+func (suite *TLSTestSuite) TestClientCertificateRotation() {
+	t := suite.T()
+	first, firstCert, firstKey, err := generateClientCertificate(1)
+	suite.Require().NoError(err)
+	second, secondCert, secondKey, err := generateClientCertificate(2)
+	suite.Require().NoError(err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(first)
+	clientCAs.AddCert(second)
+	var (
+		mu      sync.Mutex
+		serials []int64
+	)
+	mockServer := getMockServerForTLS(clientCAs, &mu, &serials)
+	defer mockServer.Close()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	suite.Require().NoError(os.WriteFile(certFile, firstCert, 0o600))
+	suite.Require().NoError(os.WriteFile(keyFile, firstKey, 0o600))
+
+	// without a client certificate the handshake fails
+	c, err := NewClient(mockServer.URL, WithLogger(suite.Logger), WithRootCAs(serverCertPool(mockServer)))
+	suite.Require().NoError(err)
+	if _, err := c.ListIndexes(); err == nil {
+		t.Errorf("Client.ListIndexes() error = nil, want client certificate error")
+	}
+
+	c, err = NewClient(mockServer.URL,
+		WithLogger(suite.Logger),
+		WithRootCAs(serverCertPool(mockServer)),
+		WithClientCertificateFiles(certFile, keyFile),
+	)
+	suite.Require().NoError(err)
+	if _, err := c.ListIndexes(); err != nil {
+		t.Fatalf("Client.ListIndexes() error = %v, want nil", err)
+	}
+
+	// rotate the certificate, new connections present the new one
+	suite.Require().NoError(os.WriteFile(certFile, secondCert, 0o600))
+	suite.Require().NoError(os.WriteFile(keyFile, secondKey, 0o600))
+	later := time.Now().Add(time.Minute)
+	suite.Require().NoError(os.Chtimes(certFile, later, later))
+	suite.Require().NoError(os.Chtimes(keyFile, later, later))
+	mockServer.CloseClientConnections()
+	if _, err := c.ListIndexes(); err != nil {
+		t.Fatalf("Client.ListIndexes() error = %v, want nil", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(serials) != 2 || serials[0] != 1 || serials[1] != 2 {
+		t.Errorf("client certificate serials = %v, want [1 2]", serials)
+	}
+}
+
+func (suite *TLSTestSuite) TestClientCertificateFilesNotFound() {
+	_, err := NewClient("https://localhost:8882",
+		WithClientCertificateFiles("missing.pem", "missing-key.pem"))
+	suite.Error(err)
+}
+
+func (suite *TLSTestSuite) TestSPKIPins() {
+	t := suite.T()
+	var (
+		mu      sync.Mutex
+		serials []int64
+	)
+	mockServer := getMockServerForTLS(nil, &mu, &serials)
+	defer mockServer.Close()
+	hash := sha256.Sum256(mockServer.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+	otherPin := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name    string
+		pins    []string
+		wantErr error
+	}{
+		{
+			name: "matching pin",
+			pins: []string{otherPin, pin},
+		},
+		{
+			name:    "no matching pin",
+			pins:    []string{otherPin},
+			wantErr: ErrCertificatePinMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(mockServer.URL,
+				WithLogger(suite.Logger),
+				WithRootCAs(serverCertPool(mockServer)),
+				WithSPKIPins(tt.pins...),
+			)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			_, err = c.ListIndexes()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.ListIndexes() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *TLSTestSuite) TestTLSWithTransport() {
+	t := suite.T()
+	var (
+		mu      sync.Mutex
+		serials []int64
+	)
+	mockServer := getMockServerForTLS(nil, &mu, &serials)
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL,
+		WithLogger(suite.Logger),
+		WithTransport(&http.Transport{}),
+		WithRootCAs(serverCertPool(mockServer)),
+	)
+	suite.Require().NoError(err)
+	if _, err := c.ListIndexes(); err != nil {
+		t.Errorf("Client.ListIndexes() error = %v, want nil", err)
+	}
+
+	_, err = NewClient(mockServer.URL,
+		WithTransport(&countingTransport{}),
+		WithRootCAs(serverCertPool(mockServer)),
+	)
+	suite.Error(err)
+}
@@ -0,0 +1,119 @@
+package marqo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/imroc/req/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation name of the tracer
+const tracerName = "github.com/ganeshdipdumbare/marqo-go"
+
+// Span attribute keys
+const (
+	attributeIndexName        = attribute.Key("marqo.index")
+	attributeSearchMethod     = attribute.Key("marqo.search_method")
+	attributeLimit            = attribute.Key("marqo.limit")
+	attributeDocumentCount    = attribute.Key("marqo.document_count")
+	attributeProcessingTimeMS = attribute.Key("marqo.processing_time_ms")
+	attributeStatusCode       = attribute.Key("http.response.status_code")
+)
+
+// WithTracerProvider enables OpenTelemetry tracing. Every client method
+// starts a span named after it when it is called, e.g. "marqo.Search",
+// which is the child of the span in the request context, so that
+// validation errors are recorded too and the spans of the requests sent by
+// methods such as EnsureIndex or Reindex are their children. The W3C trace
+// context headers are added to the requests. Retries and failovers are
+// part of the span.
+//
+// Example usage:
+//
+//	client, err := marqo.NewClient("http://localhost:8882",
+//	    marqo.WithTracerProvider(otel.GetTracerProvider()),
+//	)
+//	resp, err := client.SearchWithContext(ctx, searchReq)
+func WithTracerProvider(provider trace.TracerProvider) func(*Client) {
+	return func(c *Client) {
+		c.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// startSpan starts the span of the client method name, ended with endSpan.
+// The methods which send a single request are client spans, the ones built
+// on other methods are internal spans. It returns ctx and a no-op span if
+// tracing is disabled.
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	if c.tracer == nil {
+		return ctx, noop.Span{}
+	}
+	kind := trace.SpanKindInternal
+	if _, ok := operationSpecs[name]; ok {
+		kind = trace.SpanKindClient
+	}
+	return c.tracer.Start(ctx, "marqo."+name, trace.WithSpanKind(kind))
+}
+
+// endSpan records the error returned by a client method, if any, and ends
+// its span
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// traceRoundTrip is the round trip wrapper which adds the trace context of
+// the span of the client method to the request, and the status code and
+// processing time of the response to the span
+func (c *Client) traceRoundTrip(next req.RoundTripper) req.RoundTripFunc {
+	return func(r *req.Request) (*req.Response, error) {
+		op := operationFromContext(r.Context())
+		if r.Headers == nil {
+			r.Headers = make(http.Header)
+		}
+		propagation.TraceContext{}.Inject(r.Context(), propagation.HeaderCarrier(r.Headers))
+
+		resp, err := next.RoundTrip(r)
+		if err != nil || op.span == nil || resp == nil || resp.Response == nil {
+			return resp, err
+		}
+		op.span.SetAttributes(attributeStatusCode.Int(resp.Response.StatusCode))
+		if summary := op.summarize(resp); summary != nil && summary.ProcessingTimeMS != nil {
+			op.span.SetAttributes(attributeProcessingTimeMS.Float64(*summary.ProcessingTimeMS))
+		}
+		return resp, err
+	}
+}
+
+// operationAttributes returns the span attributes taken from the request
+// of the operation
+func operationAttributes(op *operation) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if op.indexName != "" {
+		attrs = append(attrs, attributeIndexName.String(op.indexName))
+	}
+	switch request := op.request.(type) {
+	case *SearchRequest:
+		if request.SearchMethod != nil {
+			attrs = append(attrs, attributeSearchMethod.String(*request.SearchMethod))
+		}
+		if request.Limit != nil {
+			attrs = append(attrs, attributeLimit.Int(*request.Limit))
+		}
+	case *UpsertDocumentsRequest:
+		attrs = append(attrs, attributeDocumentCount.Int(len(request.Documents)))
+	case *DeleteDocumentsRequest:
+		attrs = append(attrs, attributeDocumentCount.Int(len(request.DocumentIDs)))
+	case *GetDocumentsRequest:
+		attrs = append(attrs, attributeDocumentCount.Int(len(request.DocumentIDs)))
+	}
+	return attrs
+}
@@ -0,0 +1,213 @@
+package marqo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// getMockServerForTracing returns a server which records the traceparent
+// header and responds with statusCode and body
+func getMockServerForTracing(statusCode int, body string, traceparent *atomic.Value) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			traceparent.Store(r.Header.Get("traceparent"))
+			w.WriteHeader(statusCode)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(body))
+		}))
+}
+
+func TestTracing(t *testing.T) {
+	query := "query"
+	limit := 5
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		call       func(c *Client, ctx context.Context) error
+		wantName   string
+		wantAttrs  map[attribute.Key]attribute.Value
+		wantStatus codes.Code
+	}{
+		{
+			name:       "search",
+			statusCode: http.StatusOK,
+			body:       `{"hits": [], "processingTimeMs": 12.5}`,
+			call: func(c *Client, ctx context.Context) error {
+				_, err := c.SearchWithContext(ctx, &SearchRequest{IndexName: "test", Q: &query, Limit: &limit})
+				return err
+			},
+			wantName: "marqo.Search",
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attributeIndexName:        attribute.StringValue("test"),
+				attributeSearchMethod:     attribute.StringValue("TENSOR"),
+				attributeLimit:            attribute.IntValue(5),
+				attributeStatusCode:       attribute.IntValue(http.StatusOK),
+				attributeProcessingTimeMS: attribute.Float64Value(12.5),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "upsert documents",
+			statusCode: http.StatusOK,
+			body:       `{"errors": false, "items": [], "processingTimeMs": 3}`,
+			call: func(c *Client, ctx context.Context) error {
+				_, err := c.UpsertDocumentsWithContext(ctx, &UpsertDocumentsRequest{
+					IndexName: "test",
+					Documents: []interface{}{
+						map[string]interface{}{"_id": "1"},
+						map[string]interface{}{"_id": "2"},
+					},
+				})
+				return err
+			},
+			wantName: "marqo.UpsertDocuments",
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attributeIndexName:        attribute.StringValue("test"),
+				attributeDocumentCount:    attribute.IntValue(2),
+				attributeStatusCode:       attribute.IntValue(http.StatusOK),
+				attributeProcessingTimeMS: attribute.Float64Value(3),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "body too large to summarize",
+			statusCode: http.StatusOK,
+			body:       `{"hits": [{"title": "` + strings.Repeat("x", maxSummaryBodySize) + `"}], "processingTimeMs": 12.5}`,
+			call: func(c *Client, ctx context.Context) error {
+				_, err := c.SearchWithContext(ctx, &SearchRequest{IndexName: "test", Q: &query})
+				return err
+			},
+			wantName: "marqo.Search",
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attributeIndexName:  attribute.StringValue("test"),
+				attributeStatusCode: attribute.IntValue(http.StatusOK),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "index not found",
+			statusCode: http.StatusNotFound,
+			body:       `{"code": "index_not_found", "message": "index not found"}`,
+			call: func(c *Client, ctx context.Context) error {
+				_, err := c.GetIndexStatsWithContext(ctx, &GetIndexStatsRequest{IndexName: "missing"})
+				return err
+			},
+			wantName: "marqo.GetIndexStats",
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attributeIndexName:  attribute.StringValue("missing"),
+				attributeStatusCode: attribute.IntValue(http.StatusNotFound),
+			},
+			wantStatus: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var traceparent atomic.Value
+			mockServer := getMockServerForTracing(tt.statusCode, tt.body, &traceparent)
+			defer mockServer.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			c, err := NewClient(mockServer.URL, WithTracerProvider(provider))
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+			// nolint
+			tt.call(c, ctx)
+			parent.End()
+
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("spans = %v, want 2", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.wantName {
+				t.Errorf("span name = %v, want %v", span.Name(), tt.wantName)
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("span parent = %v, want %v", span.Parent().SpanID(), parent.SpanContext().SpanID())
+			}
+			if span.Status().Code != tt.wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status().Code, tt.wantStatus)
+			}
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, attr := range span.Attributes() {
+				attrs[attr.Key] = attr.Value
+			}
+			for key, want := range tt.wantAttrs {
+				if got, ok := attrs[key]; !ok || got != want {
+					t.Errorf("span attribute %v = %v, want %v", key, got.Emit(), want.Emit())
+				}
+			}
+			if _, ok := tt.wantAttrs[attributeProcessingTimeMS]; !ok {
+				if got, ok := attrs[attributeProcessingTimeMS]; ok {
+					t.Errorf("span attribute %v = %v, want none", attributeProcessingTimeMS, got.Emit())
+				}
+			}
+			wantTraceparent := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+			if got := traceparent.Load(); got != wantTraceparent {
+				t.Errorf("traceparent header = %v, want %v", got, wantTraceparent)
+			}
+		})
+	}
+}
+
+func TestTracing_MethodSpans(t *testing.T) {
+	var traceparent atomic.Value
+	mockServer := getMockServerForTracing(http.StatusOK, `{"results": [{"index_name": "test"}], "status": "green"}`, &traceparent)
+	defer mockServer.Close()
+
+	newClient := func(t *testing.T) (*Client, *tracetest.SpanRecorder) {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		c, err := NewClient(mockServer.URL, WithTracerProvider(provider))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		return c, recorder
+	}
+
+	t.Run("validation error", func(t *testing.T) {
+		c, recorder := newClient(t)
+		if _, err := c.SearchWithContext(context.Background(), &SearchRequest{}); err == nil {
+			t.Fatalf("Client.SearchWithContext() error = nil, want a validation error")
+		}
+		spans := recorder.Ended()
+		if len(spans) != 1 || spans[0].Name() != "marqo.Search" || spans[0].Status().Code != codes.Error {
+			t.Fatalf("spans = %v, want one failed marqo.Search span", spans)
+		}
+	})
+
+	t.Run("composite method", func(t *testing.T) {
+		c, recorder := newClient(t)
+		if err := c.WaitForIndexReady(context.Background(), "test"); err != nil {
+			t.Fatalf("Client.WaitForIndexReady() error = %v", err)
+		}
+		spans := recorder.Ended()
+		if len(spans) < 2 {
+			t.Fatalf("spans = %v, want the wait span and its children", len(spans))
+		}
+		parent := spans[len(spans)-1]
+		if parent.Name() != "marqo.WaitForIndexReady" || parent.Parent().IsValid() {
+			t.Fatalf("last span = %v, want the root marqo.WaitForIndexReady span", parent.Name())
+		}
+		for _, span := range spans[:len(spans)-1] {
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("span %v parent = %v, want %v", span.Name(), span.Parent().SpanID(), parent.SpanContext().SpanID())
+			}
+		}
+	})
+}
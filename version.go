@@ -0,0 +1,75 @@
+package marqo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetVersionResponse is the response from the server root endpoint
+type GetVersionResponse struct {
+	Message string `json:"message"`
+	Version string `json:"version"`
+}
+
+// MajorVersion returns the major version of the server, e.g. 2 for "2.5.1"
+func (r *GetVersionResponse) MajorVersion() (int, error) {
+	major, _, _ := strings.Cut(strings.TrimPrefix(r.Version, "v"), ".")
+	version, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid server version %q", r.Version)
+	}
+	return version, nil
+}
+
+// GetVersion gets the version of the server.
+//
+// This method sends a GET request to the root endpoint of the server.
+//
+// Returns:
+//
+//	*GetVersionResponse: The response containing the server version.
+//	error: An error if the operation fails, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Sends a GET request to the root endpoint of the server.
+// 2. Checks the response status code and logs any errors.
+// 3. Returns the response from the server if the operation is successful, otherwise returns an error.
+//
+// Example usage:
+//
+//	resp, err := client.GetVersion()
+//	if err != nil {
+//	    log.Fatalf("Failed to get version: %v", err)
+//	}
+//	fmt.Printf("Marqo version: %s\n", resp.Version)
+func (c *Client) GetVersion() (*GetVersionResponse, error) {
+	return c.GetVersionWithContext(context.Background())
+}
+
+// GetVersionWithContext is like GetVersion but sends the request with ctx.
+func (c *Client) GetVersionWithContext(ctx context.Context) (_ *GetVersionResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetVersion")
+	defer endSpan(span, &err)
+	logger := c.methodLogger("GetVersion")
+
+	var getVersionResp GetVersionResponse
+	resp, err := c.newRequest(ctx, "GetVersion", "", nil).
+		SetSuccessResult(&getVersionResp).
+		Get(c.reqClient.BaseURL + "/")
+	if err != nil {
+		logger.Error("error getting version", "error", err)
+		return nil, err
+	}
+	if resp.Response.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp)
+		logger.Error("error getting version", "status_code", apiErr.StatusCode, "error_code", apiErr.Code)
+		return nil, fmt.Errorf("error getting version: %w", apiErr)
+	}
+
+	c.logResponse(ctx, logger, resp, "got version",
+		"version", getVersionResp.Version)
+	return &getVersionResp, nil
+}
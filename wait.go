@@ -0,0 +1,279 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIndexFailed is returned when waiting for an index which Marqo Cloud
+// failed to create
+var ErrIndexFailed = errors.New("marqo: index failed")
+
+// Index statuses reported by Marqo Cloud in ListIndexes
+const (
+	IndexStatusReady    = "READY"
+	IndexStatusCreating = "CREATING"
+	IndexStatusDeleting = "DELETING"
+	IndexStatusFailed   = "FAILED"
+)
+
+// IndexStatus is the state of an index observed while waiting for it
+type IndexStatus struct {
+	// IndexName is the name of the index
+	IndexName string
+	// Attempt is the number of the poll, starting at 1
+	Attempt int
+	// Elapsed is the time since the wait started
+	Elapsed time.Duration
+	// Listed is whether ListIndexes returned the index
+	Listed bool
+	// IndexStatus is the Marqo Cloud status of the index, if any
+	IndexStatus string
+	// Health is the health of the index, if it was fetched
+	Health *GetIndexHealthResponse
+	// Ready is whether the index is in the state waited for
+	Ready bool
+	// Err is the error of the poll, the wait goes on after it
+	Err error
+}
+
+// waitSettings are the settings of WaitForIndexReady and WaitForIndexDeleted
+type waitSettings struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	timeout         time.Duration
+	onStatus        func(IndexStatus)
+}
+
+// WaitOption configures WaitForIndexReady and WaitForIndexDeleted
+type WaitOption func(*waitSettings)
+
+// WithPollInterval sets the wait before the second poll, doubled for every
+// following poll up to max (default: 500ms and 10s)
+func WithPollInterval(initial, max time.Duration) WaitOption {
+	return func(s *waitSettings) {
+		s.initialInterval = initial
+		s.maxInterval = max
+	}
+}
+
+// WithWaitTimeout sets how long to wait at most, in addition to the
+// deadline of the context (default: 5m, 0 waits until the context is done)
+func WithWaitTimeout(timeout time.Duration) WaitOption {
+	return func(s *waitSettings) {
+		s.timeout = timeout
+	}
+}
+
+// WithStatusCallback sets a function called with the status of the index
+// after every poll
+func WithStatusCallback(onStatus func(IndexStatus)) WaitOption {
+	return func(s *waitSettings) {
+		s.onStatus = onStatus
+	}
+}
+
+// newWaitSettings returns the default wait settings with opts applied
+func newWaitSettings(opts []WaitOption) *waitSettings {
+	s := &waitSettings{
+		initialInterval: 500 * time.Millisecond,
+		maxInterval:     10 * time.Second,
+		timeout:         5 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.initialInterval <= 0 {
+		s.initialInterval = time.Millisecond
+	}
+	if s.maxInterval < s.initialInterval {
+		s.maxInterval = s.initialInterval
+	}
+	return s
+}
+
+// WaitForIndexReady waits until the index can serve traffic.
+//
+// This method polls ListIndexes and GetIndexHealth until the index is listed,
+// its Marqo Cloud status, if any, is READY and its health is not red. An
+// alias is resolved once, before the first poll.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context which bounds the wait.
+//	indexName (string): The name of the index.
+//	opts (...WaitOption): The poll interval, timeout and status callback.
+//
+// Returns:
+//
+//	error: An error if the index is not ready in time, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Lists the indexes and gets the health of the index if it is listed.
+// 2. Reports the status of the index to the status callback.
+// 3. Returns nil if the index is ready, ErrIndexFailed if Marqo Cloud failed to create it
+// and ErrUnauthorized if the credentials are rejected.
+// 4. Otherwise waits for the poll interval, doubled after every poll, and polls again
+// until the timeout or the context deadline.
+//
+// Example usage:
+//
+//	err := client.WaitForIndexReady(ctx, "example_index",
+//	    marqo.WithPollInterval(time.Second, 10*time.Second),
+//	    marqo.WithStatusCallback(func(status marqo.IndexStatus) {
+//	        log.Printf("index status: %+v", status)
+//	    }),
+//	)
+//	if err != nil {
+//	    log.Fatalf("Index is not ready: %v", err)
+//	}
+func (c *Client) WaitForIndexReady(ctx context.Context, indexName string, opts ...WaitOption) (err error) {
+	ctx, span := c.startSpan(ctx, "WaitForIndexReady")
+	defer endSpan(span, &err)
+	indexName, err = c.resolveIndex(ctx, indexName)
+	if err != nil {
+		return err
+	}
+	// the polls must not resolve an alias shadowing the index
+	return c.waitForIndex(withPhysicalIndex(ctx), indexName, newWaitSettings(opts), c.pollIndexReady)
+}
+
+// WaitForIndexDeleted waits until the index is deleted.
+//
+// This method polls ListIndexes until the index is not listed anymore. An
+// alias is resolved once, before the first poll.
+//
+// Parameters:
+//
+//	ctx (context.Context): The context which bounds the wait.
+//	indexName (string): The name of the index.
+//	opts (...WaitOption): The poll interval, timeout and status callback.
+//
+// Returns:
+//
+//	error: An error if the index is still listed in time, otherwise nil.
+//
+// The function performs the following steps:
+// 1. Lists the indexes.
+// 2. Reports the status of the index to the status callback.
+// 3. Returns nil if the index is not listed and ErrUnauthorized if the credentials are rejected.
+// 4. Otherwise waits for the poll interval, doubled after every poll, and polls again
+// until the timeout or the context deadline.
+//
+// Example usage:
+//
+//	_, err := client.DeleteIndex(&marqo.DeleteIndexRequest{IndexName: "example_index"})
+//	if err != nil {
+//	    log.Fatalf("Failed to delete index: %v", err)
+//	}
+//	if err := client.WaitForIndexDeleted(ctx, "example_index"); err != nil {
+//	    log.Fatalf("Index is not deleted: %v", err)
+//	}
+func (c *Client) WaitForIndexDeleted(ctx context.Context, indexName string, opts ...WaitOption) (err error) {
+	ctx, span := c.startSpan(ctx, "WaitForIndexDeleted")
+	defer endSpan(span, &err)
+	indexName, err = c.resolveIndex(ctx, indexName)
+	if err != nil {
+		return err
+	}
+	// the polls must not resolve an alias shadowing the index
+	return c.waitForIndex(withPhysicalIndex(ctx), indexName, newWaitSettings(opts), c.pollIndexDeleted)
+}
+
+// waitForIndex calls poll with backoff until the index is ready, poll
+// returns a terminal error or the wait times out
+func (c *Client) waitForIndex(ctx context.Context, indexName string, s *waitSettings,
+	poll func(ctx context.Context, status *IndexStatus) error) error {
+	logger := c.methodLogger("WaitForIndex")
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	interval := s.initialInterval
+	for attempt := 1; ; attempt++ {
+		status := IndexStatus{IndexName: indexName, Attempt: attempt}
+		err := poll(ctx, &status)
+		status.Elapsed = time.Since(start)
+		status.Err = err
+		if s.onStatus != nil {
+			s.onStatus(status)
+		}
+		if status.Ready {
+			logger.Info("index is in the expected state", "index", indexName, "attempts", attempt, "duration", status.Elapsed)
+			return nil
+		}
+		if errors.Is(err, ErrIndexFailed) || errors.Is(err, ErrUnauthorized) {
+			logger.Error("error waiting for index", "index", indexName, "error", err)
+			return fmt.Errorf("error waiting for index %s: %w", indexName, err)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Error("error waiting for index", "index", indexName, "attempts", attempt, "error", ctx.Err())
+			if err != nil {
+				return fmt.Errorf("error waiting for index %s after %d attempts: %w (last error: %v)", indexName, attempt, ctx.Err(), err)
+			}
+			return fmt.Errorf("error waiting for index %s after %d attempts: %w", indexName, attempt, ctx.Err())
+		case <-timer.C:
+		}
+		interval *= 2
+		if interval > s.maxInterval {
+			interval = s.maxInterval
+		}
+	}
+}
+
+// lookupIndex sets whether the index is listed, and its Marqo Cloud status
+func (c *Client) lookupIndex(ctx context.Context, status *IndexStatus) error {
+	listIndexesResp, err := c.ListIndexesWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, result := range listIndexesResp.Results {
+		if result.IndexName == status.IndexName {
+			status.Listed = true
+			status.IndexStatus = result.IndexStatus
+			return nil
+		}
+	}
+	return nil
+}
+
+// pollIndexReady sets whether the index is ready to serve traffic
+func (c *Client) pollIndexReady(ctx context.Context, status *IndexStatus) error {
+	if err := c.lookupIndex(ctx, status); err != nil || !status.Listed {
+		return err
+	}
+	switch status.IndexStatus {
+	case "", IndexStatusReady:
+	case IndexStatusFailed:
+		return ErrIndexFailed
+	default:
+		return nil
+	}
+
+	health, err := c.GetIndexHealthWithContext(ctx, &GetIndexHealthRequest{IndexName: status.IndexName})
+	if err != nil {
+		// the index may be listed before it can be queried
+		return err
+	}
+	status.Health = health
+	status.Ready = health.Status != "red" && health.Backend.Status != "red"
+	return nil
+}
+
+// pollIndexDeleted sets whether the index is deleted
+func (c *Client) pollIndexDeleted(ctx context.Context, status *IndexStatus) error {
+	if err := c.lookupIndex(ctx, status); err != nil {
+		return err
+	}
+	status.Ready = !status.Listed
+	return nil
+}
@@ -0,0 +1,182 @@
+package marqo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// getMockServerForWait returns a server whose ListIndexes response is
+// listResponses[i] on the i-th call, and the last one afterwards, and whose
+// index health is red on the first call
+func getMockServerForWait(listResponses []string, listCalls *int32) *httptest.Server {
+	var healthCalls int32
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var resp string
+			switch r.URL.Path {
+			case "/indexes":
+				call := int(atomic.AddInt32(listCalls, 1))
+				resp = listResponses[min(call, len(listResponses))-1]
+			case "/indexes/test/health":
+				resp = `{"status": "green", "backend": {"status": "green", "storage_is_available": true}}`
+				if atomic.AddInt32(&healthCalls, 1) == 1 {
+					resp = `{"status": "red", "backend": {"status": "red", "storage_is_available": true}}`
+				}
+			default:
+				resp = `{"acknowledged": true, "index": "test"}`
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(resp))
+		}))
+}
+
+func TestClient_WaitForIndex(t *testing.T) {
+	const (
+		missing  = `{"results": []}`
+		listed   = `{"results": [{"index_name": "test"}]}`
+		creating = `{"results": [{"indexName": "test", "indexStatus": "CREATING"}]}`
+		ready    = `{"results": [{"indexName": "test", "indexStatus": "READY"}]}`
+		failed   = `{"results": [{"indexName": "test", "indexStatus": "FAILED"}]}`
+		deleting = `{"results": [{"indexName": "test", "indexStatus": "DELETING"}]}`
+	)
+
+	tests := []struct {
+		name          string
+		listResponses []string
+		wait          func(c *Client, opts ...WaitOption) error
+		wantErr       error
+		wantStatuses  int
+	}{
+		{
+			name:          "ready after listed and green",
+			listResponses: []string{missing, listed},
+			wait: func(c *Client, opts ...WaitOption) error {
+				return c.WaitForIndexReady(context.Background(), "test", opts...)
+			},
+			// missing, listed with red health, green
+			wantStatuses: 3,
+		},
+		{
+			name:          "ready after marqo cloud status",
+			listResponses: []string{creating, creating, ready},
+			wait: func(c *Client, opts ...WaitOption) error {
+				return c.WaitForIndexReady(context.Background(), "test", opts...)
+			},
+			// creating, creating, ready with red health, green
+			wantStatuses: 4,
+		},
+		{
+			name:          "failed on marqo cloud",
+			listResponses: []string{creating, failed},
+			wait: func(c *Client, opts ...WaitOption) error {
+				return c.WaitForIndexReady(context.Background(), "test", opts...)
+			},
+			wantErr:      ErrIndexFailed,
+			wantStatuses: 2,
+		},
+		{
+			name:          "timeout",
+			listResponses: []string{missing},
+			wait: func(c *Client, opts ...WaitOption) error {
+				return c.WaitForIndexReady(context.Background(), "test", append(opts, WithWaitTimeout(20*time.Millisecond))...)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:          "deleted",
+			listResponses: []string{listed, deleting, missing},
+			wait: func(c *Client, opts ...WaitOption) error {
+				return c.WaitForIndexDeleted(context.Background(), "test", opts...)
+			},
+			wantStatuses: 3,
+		},
+		{
+			name:          "create index and wait",
+			listResponses: []string{listed},
+			wait: func(c *Client, opts ...WaitOption) error {
+				_, err := c.CreateIndex(&CreateIndexRequest{IndexName: "test", Wait: true})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listCalls int32
+			mockServer := getMockServerForWait(tt.listResponses, &listCalls)
+			defer mockServer.Close()
+
+			c, err := NewClient(mockServer.URL)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			var statuses []IndexStatus
+			err = tt.wait(c,
+				WithPollInterval(time.Millisecond, 2*time.Millisecond),
+				WithStatusCallback(func(status IndexStatus) {
+					statuses = append(statuses, status)
+				}),
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("wait error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantStatuses > 0 && len(statuses) != tt.wantStatuses {
+				t.Errorf("statuses = %+v, want %d", statuses, tt.wantStatuses)
+			}
+			for i, status := range statuses {
+				if status.Attempt != i+1 || status.IndexName != "test" {
+					t.Errorf("status %d = %+v", i, status)
+				}
+			}
+			if tt.wantErr == nil && len(statuses) > 0 && !statuses[len(statuses)-1].Ready {
+				t.Errorf("last status = %+v, want ready", statuses[len(statuses)-1])
+			}
+		})
+	}
+}
+
+func TestClient_WaitForIndexShadowedByAlias(t *testing.T) {
+	var healthPaths []string
+	mockServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			resp := `{"acknowledged": true, "index": "test"}`
+			switch r.URL.Path {
+			case "/indexes":
+				resp = `{"results": [{"index_name": "test"}, {"index_name": "other"}]}`
+			case "/indexes/test/health", "/indexes/other/health":
+				healthPaths = append(healthPaths, r.URL.Path)
+				resp = `{"status": "green", "backend": {"status": "green"}}`
+			}
+			w.WriteHeader(http.StatusOK)
+			// disabeling lint as this is a mock server
+			// nolint
+			w.Write([]byte(resp))
+		}))
+	defer mockServer.Close()
+
+	c, err := NewClient(mockServer.URL, WithAliasStore(NewMemoryAliasStore()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	// the alias test shadows the index test, which products points to
+	for alias, index := range map[string]string{"products": "test", "test": "other"} {
+		if err := c.SetAlias(context.Background(), alias, index); err != nil {
+			t.Fatalf("SetAlias() error = %v", err)
+		}
+	}
+	if err := c.WaitForIndexReady(context.Background(), "products"); err != nil {
+		t.Fatalf("Client.WaitForIndexReady() error = %v", err)
+	}
+	if _, err := c.CreateIndex(&CreateIndexRequest{IndexName: "test", Wait: true}); err != nil {
+		t.Fatalf("Client.CreateIndex() error = %v", err)
+	}
+	if len(healthPaths) != 2 || healthPaths[0] != "/indexes/test/health" || healthPaths[1] != "/indexes/test/health" {
+		t.Errorf("health requests = %v, want the index test twice", healthPaths)
+	}
+}